@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"net/http"
+
+	"lingolift/api"
+	"lingolift/api/handler/params"
+	"lingolift/api/handler/response"
+	"lingolift/api/middleware"
+	"lingolift/config"
+	"lingolift/errno"
+	"lingolift/pkg/classroom"
+	"lingolift/pkg/record"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateClass 创建班级，调用者为班级教师
+func CreateClass(c echo.Context) error {
+	teacherID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	var p params.Class
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+
+	class, err := classroom.CreateClass(middleware.TenantName(c.Request()), p.Name, teacherID)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, class)
+}
+
+// ListClasses 调用者创建的班级
+func ListClasses(c echo.Context) error {
+	teacherID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	list := classroom.ListClasses(middleware.TenantName(c.Request()), teacherID)
+	if list == nil {
+		list = []*classroom.Class{}
+	}
+	return api.Return(c, list)
+}
+
+// GetClass 班级详情
+func GetClass(c echo.Context) error {
+	class, err := teacherClass(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, class)
+}
+
+// ListMembers 班级成员
+func ListMembers(c echo.Context) error {
+	class, err := teacherClass(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	list := classroom.ListMembers(class.ID)
+	if list == nil {
+		list = []*classroom.Member{}
+	}
+	return api.Return(c, list)
+}
+
+// AddMember 邀请学生加入班级，学生接受后才成为班级成员
+func AddMember(c echo.Context) error {
+	class, err := teacherClass(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	var p params.Member
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+
+	member, err := classroom.AddMember(class, p.UserID)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, member)
+}
+
+// RemoveMember 移除班级成员，学生本人可以拒绝邀请或退出班级
+func RemoveMember(c echo.Context) error {
+	userID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	classID := c.Param("id")
+	if userID != c.Param("user_id") {
+		class, err := teacherClass(c)
+		if err != nil {
+			return api.ReturnErr(c, err)
+		}
+		classID = class.ID
+	}
+
+	if err = classroom.RemoveMember(middleware.TenantName(c.Request()), classID, c.Param("user_id")); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.ReturnSuccess(c)
+}
+
+// ListInvitations 调用者尚未接受的班级邀请
+func ListInvitations(c echo.Context) error {
+	userID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	list := classroom.ListInvitations(middleware.TenantName(c.Request()), userID)
+	if list == nil {
+		list = []*classroom.Member{}
+	}
+	return api.Return(c, list)
+}
+
+// JoinClass 调用者接受班级邀请
+func JoinClass(c echo.Context) error {
+	userID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	member, err := classroom.AcceptInvitation(middleware.TenantName(c.Request()), c.Param("id"), userID)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, member)
+}
+
+// ListAssignments 班级的作业
+func ListAssignments(c echo.Context) error {
+	class, err := teacherClass(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	list := classroom.ListAssignments(class.ID)
+	if list == nil {
+		list = []*classroom.Assignment{}
+	}
+	return api.Return(c, list)
+}
+
+// CreateAssignment 给班级布置作业
+func CreateAssignment(c echo.Context) error {
+	class, err := teacherClass(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	var p params.Assignment
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+
+	// 考试记录需要签名，评分配置必须是服务端已配置的
+	if p.Exam != nil {
+		if !record.CanSign() {
+			return api.ReturnError(c, errno.ErrOperateFailed.WithFmt("exam mode requires record_conf.signing_key."))
+		}
+		if _, err = config.Current().Scoring.Lookup(p.Exam.ScoringProfile); err != nil {
+			return api.ReturnErr(c, err)
+		}
+	}
+
+	assignment := &classroom.Assignment{
+		ClassID: class.ID,
+		Title:   p.Title,
+		ItemIDs: p.ItemIDs,
+		DueAt:   p.DueAt,
+		Exam:    p.Exam,
+	}
+	if err = classroom.CreateAssignment(middleware.TenantName(c.Request()), assignment); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, assignment)
+}
+
+// ListSubmissions 作业的提交情况：每个学生的完成数、各条目得分和平均分
+func ListSubmissions(c echo.Context) error {
+	assignment, err := teacherAssignment(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	students := classroom.Progress(assignment)
+	if students == nil {
+		students = []*classroom.StudentProgress{}
+	}
+	return api.Return(c, response.Submissions{
+		Assignment: assignment,
+		Students:   students,
+	})
+}
+
+// ExportGrades 以 CSV 导出作业成绩
+func ExportGrades(c echo.Context) error {
+	assignment, err := teacherAssignment(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	data, err := classroom.GradesCSV(assignment)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		`attachment; filename="grades-`+assignment.ID+`.csv"`)
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// callerID 从请求头获取调用者的用户 ID
+func callerID(c echo.Context) (string, error) {
+	id := c.Request().Header.Get(config.HEADER_X_KSC_ACCOUNT_ID)
+	if len(id) == 0 {
+		return "", errno.ErrMissingHeader.WithFmt(config.HEADER_X_KSC_ACCOUNT_ID)
+	}
+	return id, nil
+}
+
+// checkUser 检查调用者是否可以查看该用户的数据：本人或其已加入班级的教师
+func checkUser(c echo.Context, userID string) error {
+	id, err := callerID(c)
+	if err != nil {
+		return err
+	}
+	if len(userID) == 0 || (id != userID && !classroom.IsTeacherOf(middleware.TenantName(c.Request()), id, userID)) {
+		return *errno.ErrPermissionDenied
+	}
+	return nil
+}
+
+// teacherClass 查询路径中的班级，并检查调用者是否为该班级的教师
+func teacherClass(c echo.Context) (*classroom.Class, error) {
+	teacherID, err := callerID(c)
+	if err != nil {
+		return nil, err
+	}
+	return classroom.TeacherClass(middleware.TenantName(c.Request()), c.Param("id"), teacherID)
+}
+
+// teacherAssignment 查询路径中的作业，并检查调用者是否为所属班级的教师
+func teacherAssignment(c echo.Context) (*classroom.Assignment, error) {
+	teacherID, err := callerID(c)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment, err := classroom.GetAssignment(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = classroom.TeacherClass(middleware.TenantName(c.Request()), assignment.ClassID, teacherID); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"lingolift/api"
+	"lingolift/api/handler/params"
+	"lingolift/api/middleware"
+	"lingolift/errno"
+	"lingolift/pkg/content"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 导入文件大小上限
+const maxImportSize = 10 << 20
+
+// ListLessons 租户的课程列表
+func ListLessons(c echo.Context) error {
+	list := content.ListLessons(middleware.TenantName(c.Request()))
+	if list == nil {
+		list = []*content.Lesson{}
+	}
+	return api.Return(c, list)
+}
+
+// GetLesson 课程详情
+func GetLesson(c echo.Context) error {
+	lesson, err := content.TenantLesson(middleware.TenantName(c.Request()), c.Param("id"))
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, lesson)
+}
+
+// SaveLesson 创建课程（POST），调用者为课程作者；或由作者更新课程（PUT）
+func SaveLesson(c echo.Context) error {
+	authorID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	var p params.Lesson
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+	tenant := middleware.TenantName(c.Request())
+	if len(p.ID) > 0 {
+		if _, err = content.AuthorLesson(tenant, p.ID, authorID); err != nil {
+			return api.ReturnErr(c, err)
+		}
+	}
+
+	lesson := &content.Lesson{
+		ID:          p.ID,
+		Tenant:      tenant,
+		AuthorID:    authorID,
+		Title:       p.Title,
+		Description: p.Description,
+		Tags:        p.Tags,
+	}
+	if err := content.SaveLesson(lesson); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, lesson)
+}
+
+// DeleteLesson 删除课程及其条目
+func DeleteLesson(c echo.Context) error {
+	lesson, err := authorLesson(c, c.Param("id"))
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	if err = content.DeleteLesson(lesson.ID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.ReturnSuccess(c)
+}
+
+// ListItems 课程下的条目列表
+func ListItems(c echo.Context) error {
+	lessonID := c.Param("lesson_id")
+	if _, err := content.TenantLesson(middleware.TenantName(c.Request()), lessonID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	list := content.ListItems(lessonID)
+	if list == nil {
+		list = []*content.Item{}
+	}
+	return api.Return(c, list)
+}
+
+// GetItem 条目详情
+func GetItem(c echo.Context) error {
+	item, err := content.TenantItem(middleware.TenantName(c.Request()), c.Param("id"))
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, item)
+}
+
+// SaveItem 在课程下创建条目（POST），或更新已有条目（PUT）
+func SaveItem(c echo.Context) error {
+	var p params.Item
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+
+	if len(p.ID) > 0 {
+		prev, err := content.TenantItem(middleware.TenantName(c.Request()), p.ID)
+		if err != nil {
+			return api.ReturnErr(c, err)
+		}
+		p.LessonID = prev.LessonID
+	}
+	if _, err := authorLesson(c, p.LessonID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	item := &content.Item{
+		ID:               p.ID,
+		LessonID:         p.LessonID,
+		RefText:          p.RefText,
+		EvalMode:         p.EvalMode,
+		ServerEngineType: p.ServerEngineType,
+		PhoneticHints:    p.PhoneticHints,
+		Tags:             p.Tags,
+		Difficulty:       p.Difficulty,
+	}
+	if err := content.SaveItem(item); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.Return(c, item)
+}
+
+// DeleteItem 删除条目
+func DeleteItem(c echo.Context) error {
+	item, err := content.TenantItem(middleware.TenantName(c.Request()), c.Param("id"))
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	if _, err = authorLesson(c, item.LessonID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	if err = content.DeleteItem(item.ID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+	return api.ReturnSuccess(c)
+}
+
+// ExportItems 按 format（json、yaml、csv）导出课程下的条目
+func ExportItems(c echo.Context) error {
+	p, err := bindTransfer(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	data, err := content.EncodeItems(p.Format, content.ListItems(p.LessonID))
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		`attachment; filename="`+p.LessonID+`.`+p.Format+`"`)
+	return c.Blob(http.StatusOK, content.ContentType(p.Format), data)
+}
+
+// ImportItems 按 format（json、yaml、csv）导入条目到课程，任一条目校验失败时全部不导入
+func ImportItems(c echo.Context) error {
+	p, err := bindTransfer(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	if _, err = authorLesson(c, p.LessonID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, maxImportSize))
+	if err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+
+	list, err := content.DecodeItems(p.Format, data)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	if err = content.ImportItems(p.LessonID, list); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	return api.Return(c, list)
+}
+
+// bindTransfer 解析导入导出参数，未指定 format 时默认为 json
+func bindTransfer(c echo.Context) (*params.ContentTransfer, error) {
+	p := &params.ContentTransfer{
+		LessonID: c.Param("lesson_id"),
+		Format:   strings.ToLower(c.QueryParam("format")),
+	}
+	if len(p.Format) == 0 {
+		p.Format = content.FormatJSON
+	}
+	if _, err := content.TenantLesson(middleware.TenantName(c.Request()), p.LessonID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// authorLesson 查询租户的课程，并检查调用者是否为课程作者
+func authorLesson(c echo.Context, id string) (*content.Lesson, error) {
+	authorID, err := callerID(c)
+	if err != nil {
+		return nil, err
+	}
+	return content.AuthorLesson(middleware.TenantName(c.Request()), id, authorID)
+}
@@ -1,10 +1,16 @@
-package handler
-
-import "github.com/labstack/echo/v4"
-
-// Health
-func Health(c echo.Context) error {
-	return c.JSON(200, map[string]bool{
-		"health": true,
-	})
-}
+package handler
+
+import (
+	"lingolift/api/handler/response"
+	"lingolift/pkg/upstream"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Health 服务状态和上游熔断器状态，熔断器打开时服务仍可用但新会话会快速失败
+func Health(c echo.Context) error {
+	return c.JSON(200, response.Health{
+		Health:   true,
+		Upstream: upstream.States(),
+	})
+}
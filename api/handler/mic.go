@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"lingolift/api/handler/response"
+	"lingolift/errno"
+	"lingolift/pkg/audio"
+	"lingolift/pkg/speech"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// 麦克风检测最多接收的音频时长（秒）
+	micCheckMaxDuration = 10
+)
+
+// MicCheck 麦克风检测
+// 与 /ws/assessment 使用相同的消息格式（可选的 JSON 配置、二进制音频帧、结束标志），
+// 只在本地做音频质量分析，不会调用评测服务。
+func MicCheck(c echo.Context) error {
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return err
+	}
+	defer conn.Close()
+
+	var (
+		req       speech.AssessmentRequest
+		declared  = audio.DefaultFormat
+		format    = audio.DefaultFormat
+		samples   []int16
+		firstRecv time.Time
+		lastRecv  time.Time
+	)
+
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return nil
+		}
+
+		if messageType == websocket.TextMessage {
+			var endMsg EndMessage
+			if json.Unmarshal(message, &endMsg) == nil && endMsg.Type == "end" {
+				break
+			}
+
+			// 配置消息只需在音频之前发送
+			if len(samples) == 0 {
+				if err := json.Unmarshal(message, &req); err != nil {
+					conn.WriteJSON(micError(errno.ErrInvalidParameterValue.WithFmt(
+						fmt.Sprintf("The config message is not valid JSON: %v.", err))))
+					return nil
+				}
+				declared = req.AudioFormat()
+				format = declared
+			}
+			continue
+		}
+
+		if messageType != websocket.BinaryMessage {
+			continue
+		}
+
+		chunk, f, err := audio.Decode(message, format)
+		if err != nil {
+			conn.WriteJSON(micError(errno.ErrInvalidAudio.WithRawErr(err)))
+			return nil
+		}
+		format = f
+
+		now := time.Now()
+		if firstRecv.IsZero() {
+			firstRecv = now
+		}
+		lastRecv = now
+		samples = append(samples, chunk...)
+
+		conn.WriteJSON(response.MicCheckResponse{
+			Status: "level",
+			Level: &response.MicLevel{
+				RMS:  audio.Level(chunk),
+				Peak: audio.PeakLevel(chunk),
+			},
+		})
+
+		if format.SampleRate > 0 && len(samples) >= format.SampleRate*micCheckMaxDuration {
+			break
+		}
+	}
+
+	// 接收时长不足 1 秒时无法可靠估计实际采样率
+	var received float64
+	if elapsed := lastRecv.Sub(firstRecv).Seconds(); elapsed >= 1 {
+		received = float64(len(samples)) / elapsed
+	}
+
+	quality := audio.Analyze(samples, format)
+	issues := audio.Check(quality, declared, received)
+
+	log.Printf("麦克风检测完成: Duration=%.2fs, Speech=%.1fdBFS, SNR=%.1fdB, Issues=%d",
+		quality.Duration, quality.SpeechLevel, quality.SNR, len(issues))
+
+	conn.WriteJSON(response.MicCheckResponse{
+		Status:  "complete",
+		Quality: &quality,
+		Issues:  issues,
+		Passed:  audio.Passed(issues),
+	})
+
+	return nil
+}
+
+// micError 错误响应，错误码与评测接口相同
+func micError(err error) response.MicCheckResponse {
+	e := speech.NewErrorResponse(err)
+	return response.MicCheckResponse{
+		Status:    e.Status,
+		Code:      e.Code,
+		Error:     e.Error,
+		Retryable: e.Retryable,
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"log"
+	"time"
+
+	"lingolift/api"
+	"lingolift/api/middleware"
+	"lingolift/errno"
+	"lingolift/pkg/classroom"
+	"lingolift/pkg/monitor"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// 监控连接的写超时和心跳间隔
+	monitorWriteTimeout = 10 * time.Second
+	monitorPingInterval = 30 * time.Second
+)
+
+// Monitor 教师实时查看班级学生的评测过程
+// 连接时需携带 class_id 参数，调用者必须是该班级的教师。
+// 连接后先收到班级中进行中会话的最近事件，之后实时收到每条评测响应。
+func Monitor(c echo.Context) error {
+	teacherID, err := callerID(c)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	classID := c.QueryParam("class_id")
+	if len(classID) == 0 {
+		return api.ReturnError(c, errno.ErrMissingParameter.WithFmt("class_id"))
+	}
+	if _, err = classroom.TeacherClass(middleware.TenantName(c.Request()), classID, teacherID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return err
+	}
+	defer conn.Close()
+
+	sub, snapshot := monitor.Subscribe(classID)
+	defer monitor.Unsubscribe(sub)
+
+	log.Printf("教师 %s 开始监控班级 %s", teacherID, classID)
+
+	// 监控端只接收消息，读协程用于检测连接断开
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(v interface{}) bool {
+		conn.SetWriteDeadline(time.Now().Add(monitorWriteTimeout))
+		if err := conn.WriteJSON(v); err != nil {
+			log.Printf("Monitor write error: %v", err)
+			return false
+		}
+		return true
+	}
+
+	for _, e := range snapshot {
+		if !write(e) {
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(monitorPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok || !write(e) {
+				return nil
+			}
+
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(monitorWriteTimeout)); err != nil {
+				return nil
+			}
+
+		case <-closed:
+			log.Printf("教师 %s 结束监控班级 %s", teacherID, classID)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package params
+
+import (
+	"time"
+
+	"lingolift/pkg/classroom"
+)
+
+// Class 创建班级的参数
+type Class struct {
+	Name string `json:"name"`
+}
+
+// Member 添加班级成员的参数
+type Member struct {
+	ClassID string `param:"id" json:"-"`
+	UserID  string `json:"user_id"`
+}
+
+// Assignment 布置作业的参数
+type Assignment struct {
+	ClassID string    `param:"id" json:"-"`
+	Title   string    `json:"title"`
+	ItemIDs []string  `json:"item_ids"`
+	DueAt   time.Time `json:"due_at"`
+
+	// Exam 考试模式配置
+	Exam *classroom.ExamPolicy `json:"exam"`
+}
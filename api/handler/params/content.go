@@ -0,0 +1,27 @@
+package params
+
+// Lesson 创建、更新课程的参数，ID 只从路径读取
+type Lesson struct {
+	ID          string   `param:"id" json:"-"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// Item 创建、更新条目的参数，ID 只从路径读取
+type Item struct {
+	ID               string   `param:"id" json:"-"`
+	LessonID         string   `param:"lesson_id" json:"-"`
+	RefText          string   `json:"ref_text"`
+	EvalMode         *int64   `json:"eval_mode"`
+	ServerEngineType string   `json:"server_engine_type"`
+	PhoneticHints    string   `json:"phonetic_hints"`
+	Tags             []string `json:"tags"`
+	Difficulty       int      `json:"difficulty"`
+}
+
+// ContentTransfer 导入、导出条目的参数
+type ContentTransfer struct {
+	LessonID string `param:"lesson_id"`
+	Format   string `query:"format"`
+}
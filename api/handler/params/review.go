@@ -0,0 +1,7 @@
+package params
+
+// ReviewQueue 查询复习队列的参数
+type ReviewQueue struct {
+	UserID string `param:"id"`
+	Limit  int    `query:"limit"`
+}
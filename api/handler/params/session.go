@@ -0,0 +1,7 @@
+package params
+
+// CompareSessions 对比两次评测的参数
+type CompareSessions struct {
+	A string `query:"a"`
+	B string `query:"b"`
+}
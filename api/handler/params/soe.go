@@ -1 +1 @@
-package params
+package params
@@ -0,0 +1,9 @@
+package response
+
+import "lingolift/pkg/classroom"
+
+// Submissions 作业的提交情况
+type Submissions struct {
+	Assignment *classroom.Assignment        `json:"assignment"`
+	Students   []*classroom.StudentProgress `json:"students"`
+}
@@ -0,0 +1,9 @@
+package response
+
+import "lingolift/pkg/upstream"
+
+// Health 健康检查响应
+type Health struct {
+	Health   bool              `json:"health"`
+	Upstream []upstream.Status `json:"upstream"`
+}
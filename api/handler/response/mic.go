@@ -0,0 +1,26 @@
+package response
+
+import (
+	"lingolift/pkg/audio"
+)
+
+// MicLevel 实时电平
+type MicLevel struct {
+	RMS  float64 `json:"rms_dbfs"`
+	Peak float64 `json:"peak_dbfs"`
+}
+
+// MicCheckResponse 麦克风检测响应
+// status: level（实时电平）、complete（检测结果）、error
+type MicCheckResponse struct {
+	Status  string         `json:"status"`
+	Level   *MicLevel      `json:"level,omitempty"`
+	Quality *audio.Quality `json:"quality,omitempty"`
+	Issues  []audio.Issue  `json:"issues,omitempty"`
+	Passed  bool           `json:"passed"`
+
+	// 错误响应的错误码、信息和是否可重试，与评测接口相同
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Retryable *bool  `json:"retryable,omitempty"`
+}
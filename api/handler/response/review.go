@@ -0,0 +1,9 @@
+package response
+
+import "lingolift/pkg/review"
+
+// ReviewQueue 用户的复习队列
+type ReviewQueue struct {
+	UserID string         `json:"user_id"`
+	Items  []*review.Card `json:"items"`
+}
@@ -0,0 +1,8 @@
+package response
+
+// SessionVerification 评测记录签名校验结果
+type SessionVerification struct {
+	ID     string `json:"id"`
+	Locked bool   `json:"locked"`
+	Valid  bool   `json:"valid"`
+}
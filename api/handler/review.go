@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"time"
+
+	"lingolift/api"
+	"lingolift/api/handler/params"
+	"lingolift/api/handler/response"
+	"lingolift/api/middleware"
+	"lingolift/errno"
+	"lingolift/pkg/review"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReviewQueue 返回用户接下来需要复习的单词
+func ReviewQueue(c echo.Context) error {
+	var p params.ReviewQueue
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+	if p.Limit < 0 {
+		return api.ReturnError(c, errno.ErrInvalidParameterValue.WithFmt("limit must not be negative."))
+	}
+
+	if err := checkUser(c, p.UserID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	cards := review.Due(middleware.TenantName(c.Request()), p.UserID, time.Now(), p.Limit)
+	if cards == nil {
+		cards = []*review.Card{}
+	}
+
+	return api.Return(c, response.ReviewQueue{
+		UserID: p.UserID,
+		Items:  cards,
+	})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+
+	"lingolift/api"
+	"lingolift/api/handler/params"
+	"lingolift/api/handler/response"
+	"lingolift/api/middleware"
+	"lingolift/errno"
+	"lingolift/pkg/record"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CompareSessions 对比同一参考文本的两次评测，返回 b 相对 a 的变化
+func CompareSessions(c echo.Context) error {
+	var p params.CompareSessions
+	if err := c.Bind(&p); err != nil {
+		return api.ReturnError(c, errno.ErrInvalidParameter.WithRawErr(err))
+	}
+	if len(p.A) == 0 {
+		return api.ReturnError(c, errno.ErrMissingParameter.WithFmt("a"))
+	}
+	if len(p.B) == 0 {
+		return api.ReturnError(c, errno.ErrMissingParameter.WithFmt("b"))
+	}
+
+	a, err := tenantRecord(c, p.A)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	b, err := tenantRecord(c, p.B)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	// 只能对比自己的评测，教师可以对比班级学生的评测
+	for _, r := range []*record.Record{a, b} {
+		if err = checkUser(c, r.UserID); err != nil {
+			return api.ReturnErr(c, err)
+		}
+	}
+
+	comparison, err := record.Compare(a, b)
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	return api.Return(c, comparison)
+}
+
+// VerifySession 校验锁定的评测记录（考试记录）是否被修改过
+func VerifySession(c echo.Context) error {
+	r, err := tenantRecord(c, c.Param("id"))
+	if err != nil {
+		return api.ReturnErr(c, err)
+	}
+	if err = checkUser(c, r.UserID); err != nil {
+		return api.ReturnErr(c, err)
+	}
+
+	return api.Return(c, response.SessionVerification{
+		ID:     r.ID,
+		Locked: r.Locked,
+		Valid:  record.Verify(r),
+	})
+}
+
+// tenantRecord 查询调用者所在租户的评测记录，其他租户的记录视为不存在
+func tenantRecord(c echo.Context, id string) (*record.Record, error) {
+	r, err := record.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Tenant != middleware.TenantName(c.Request()) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("session %q", id))
+	}
+	return r, nil
+}
@@ -1,267 +1,492 @@
-package handler
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-	"strings"
-	"sync"
-	"time"
-	"unicode"
-	"unicode/utf8"
-
-	"lingolift/config"
-	"lingolift/pkg/speech"
-
-	"github.com/gorilla/websocket"
-	"github.com/labstack/echo/v4"
-	"github.com/tencentcloud/tencentcloud-speech-sdk-go/common"
-	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
-)
-
-var (
-	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
-	}
-)
-
-type EndMessage struct {
-	Type string `json:"type"`
-}
-
-// StreamAssessment
-func StreamAssessment(c echo.Context) error {
-	// 升级HTTP连接为WebSocket连接
-	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
-		return err
-	}
-	defer conn.Close()
-
-	log.Println("新的WebSocket连接已建立")
-
-	mimeType := c.Request().Header.Get("Content-Type")
-	if mimeType == "" {
-		mimeType = "audio/webm;codecs=opus"
-	}
-	log.Printf("等待接受的音频类型: %s", mimeType)
-
-	// 读取初始配置消息
-	mt, message, err := conn.ReadMessage()
-	if err != nil {
-		log.Printf("Read initial message error: %v", err)
-		return nil
-	}
-
-	// 确保是文本消息
-	if mt != websocket.TextMessage {
-		log.Printf("Initial message is not text type: %d", mt)
-		conn.WriteJSON(speech.AssessmentResponse{
-			Status: "error",
-			Error:  "初始配置必须是JSON文本消息",
-		})
-		return nil
-	}
-
-	// 调试输出原始消息
-	log.Printf("原始配置消息: %s", string(message))
-
-	// 检查消息是否包含UTF-8编码
-	if !utf8.Valid(message) {
-		log.Printf("配置消息包含非UTF-8编码")
-		conn.WriteJSON(speech.AssessmentResponse{
-			Status: "error",
-			Error:  "配置消息格式错误，请检查编码",
-		})
-		return nil
-	}
-
-	// 解析配置
-	var req speech.AssessmentRequest
-	if err = json.Unmarshal(message, &req); err != nil {
-		log.Printf("Parse config error: %v", err)
-		conn.WriteJSON(speech.AssessmentResponse{
-			Status: "error",
-			Error:  "音频有错误，重新录制即可",
-		})
-		return nil
-	}
-
-	if req.ScoreCoeff <= 0 {
-		req.ScoreCoeff = 1
-	}
-
-	if isSentence(req.RefText) {
-		req.EvalMode = 1
-	}
-
-	log.Printf("收到配置: RefText=%s, EngineType=%s, EvalMode=%d, ScoreCoeff=%.2f",
-		req.RefText, req.ServerEngineType, req.EvalMode, req.ScoreCoeff)
-
-	// 创建流式监听器
-	listener := speech.NewStreamListener(conn)
-
-	// 认证信息
-	credential := common.NewCredential(config.G.Speech.SecretID, config.G.Speech.SecretKey)
-
-	// 创建识别器
-	recognizer := soe.NewSpeechRecognizer(config.G.Speech.AppID, credential, listener)
-	recognizer.VoiceFormat = soe.AudioFormatWav
-	recognizer.RefText = req.RefText
-	recognizer.ServerEngineType = req.ServerEngineType
-	recognizer.ScoreCoeff = req.ScoreCoeff // 使用客户端传递的系数
-	recognizer.EvalMode = req.EvalMode
-	recognizer.TextMode = req.TextMode
-
-	// 启动识别器
-	log.Println("准备启动识别器...")
-	if err = recognizer.Start(); err != nil {
-		log.Printf("Recognizer start error: %v", err)
-		conn.WriteJSON(speech.AssessmentResponse{
-			Status: "error",
-			Error:  err.Error(),
-		})
-		return nil
-	}
-	log.Println("识别器已成功启动")
-
-	// 确保识别器在结束时停止
-	defer func() {
-		log.Println("正在停止识别器...")
-		recognizer.Stop()
-		log.Println("识别器已停止")
-	}()
-
-	// 创建音频文件用于调试
-	var audioFile *os.File
-	if req.IsSaveAudioFile {
-		fileName := generateUniqueFilename(mimeType)
-		audioFile, err := os.Create(fileName)
-		if err != nil {
-			log.Printf("创建音频文件失败: %v", err)
-		} else {
-			defer audioFile.Close()
-			log.Printf("音频将保存到: %s", fileName)
-		}
-	}
-
-	var (
-		totalBytes  int
-		startTime   = time.Now()
-		audioChunks [][]byte
-	)
-
-	// 处理WebSocket消息
-	var wg sync.WaitGroup
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-		defer log.Println("音频处理协程已退出")
-
-		for {
-			messageType, message, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("WebSocket read error: %v", err)
-				}
-				listener.ErrorChan <- err
-				return
-			}
-
-			// 处理结束标志
-			if messageType == websocket.TextMessage {
-				var endMsg EndMessage
-				if json.Unmarshal(message, &endMsg) == nil && endMsg.Type == "end" {
-					log.Println("收到客户端结束标志")
-
-					// 计算音频时长
-					duration := float64(totalBytes) / (16000 * 2) // 16kHz, 16bit, 单声道
-					log.Printf("音频接收完成: Total=%dByte, Estimated duration=%.2fs, cost=%.2fs",
-						totalBytes, duration, time.Since(startTime).Seconds())
-
-					// 主动通知SDK音频传输结束
-					log.Println("通知识别器音频传输结束")
-					recognizer.Stop()
-
-					return
-				}
-				// 忽略其他文本消息
-				continue
-			}
-
-			// 只处理二进制消息
-			if messageType != websocket.BinaryMessage {
-				continue
-			}
-
-			// 记录音频数据
-			totalBytes += len(message)
-			audioChunks = append(audioChunks, message)
-
-			if req.IsSaveAudioFile {
-				// 写入文件（用于调试）
-				if audioFile != nil {
-					if _, err := audioFile.Write(message); err != nil {
-						log.Printf("写入音频文件失败: %v", err)
-					}
-				}
-			}
-
-			// 发送音频数据到识别器
-			log.Printf("发送音频块到识别器: Size=%dByte, Total=%dByte", len(message), totalBytes)
-			if err := recognizer.Write(message); err != nil {
-				log.Printf("Recognizer write error: %v", err)
-				listener.ErrorChan <- err
-				return
-			}
-		}
-	}()
-
-	// 监听结果
-	select {
-	case err := <-listener.ErrorChan:
-		log.Printf("Assessment error: %v", err)
-		return nil
-
-	case <-listener.Complete:
-		log.Println("识别器流程已完整结束: Assessment completed")
-		wg.Wait()
-		return nil
-	}
-}
-
-// 生成唯一文件名
-func generateUniqueFilename(mimeType string) string {
-	timestamp := time.Now().Format("20060102150405")
-	return fmt.Sprintf("audio_%s.wav", timestamp)
-}
-
-func isSentence(s string) bool {
-	// 检查是否包含句子分隔符
-	if strings.ContainsAny(s, ".?!") {
-		return true
-	}
-
-	// 检查是否包含空格
-	if strings.Contains(s, " ") {
-		return true
-	}
-
-	// 检查是否包含多个单词（通过判断是否有大写字母在非首字符位置）
-	runes := []rune(s)
-	if len(runes) > 1 {
-		for i := 1; i < len(runes); i++ {
-			if unicode.IsUpper(runes[i]) {
-				return true
-			}
-		}
-	}
-
-	return false
-}
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"lingolift/api"
+	"lingolift/api/middleware"
+	"lingolift/config"
+	"lingolift/errno"
+	"lingolift/pkg/anticheat"
+	"lingolift/pkg/classroom"
+	"lingolift/pkg/content"
+	"lingolift/pkg/monitor"
+	"lingolift/pkg/record"
+	"lingolift/pkg/review"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/upstream"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+var (
+	upgrader = websocket.Upgrader{
+		CheckOrigin: checkOrigin,
+	}
+)
+
+type EndMessage struct {
+	Type string `json:"type"`
+}
+
+// StreamAssessment
+func StreamAssessment(c echo.Context) error {
+	// 整个会话使用同一版本的配置，重新加载只影响新会话
+	cfg := config.Current()
+
+	// 占用租户的会话名额，租户由 ResolveTenant 按 API Key 确定
+	tenant := middleware.CurrentTenant(c.Request())
+	release, err := tenant.Acquire()
+	if err != nil {
+		log.Printf("Tenant session rejected: %v", err)
+		return api.ReturnErr(c, err)
+	}
+	defer release()
+
+	// 学习者身份以请求头为准，客户端消息中的 user_id 不可信；未登录时只能匿名练习
+	userID, _ := callerID(c)
+
+	// 升级HTTP连接为WebSocket连接
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return err
+	}
+	defer conn.Close()
+
+	log.Println("新的WebSocket连接已建立")
+
+	mimeType := c.Request().Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "audio/webm;codecs=opus"
+	}
+	log.Printf("等待接受的音频类型: %s", mimeType)
+
+	// 读取初始配置消息
+	mt, message, err := conn.ReadMessage()
+	if err != nil {
+		log.Printf("Read initial message error: %v", err)
+		return nil
+	}
+
+	// 确保是文本消息
+	if mt != websocket.TextMessage {
+		log.Printf("Initial message is not text type: %d", mt)
+		conn.WriteJSON(speech.NewErrorResponse(errno.ErrInvalidParameterValue.WithFmt(
+			"The initial message must be a JSON text message.")))
+		return nil
+	}
+
+	// 调试输出原始消息
+	log.Printf("原始配置消息: %s", string(message))
+
+	// 检查消息是否包含UTF-8编码
+	if !utf8.Valid(message) {
+		log.Printf("配置消息包含非UTF-8编码")
+		conn.WriteJSON(speech.NewErrorResponse(errno.ErrInvalidParameterValue.WithFmt(
+			"The initial message must be UTF-8 encoded.")))
+		return nil
+	}
+
+	// 解析配置
+	req := speech.AssessmentRequest{EvalMode: speech.EvalModeAuto}
+	if err = json.Unmarshal(message, &req); err != nil {
+		log.Printf("Parse config error: %v", err)
+		conn.WriteJSON(speech.NewErrorResponse(errno.ErrInvalidParameterValue.WithFmt(
+			fmt.Sprintf("The initial message is not valid JSON: %v.", err))))
+		return nil
+	}
+
+	// 不能以其他用户的身份评测、提交作业或考试
+	if len(req.UserID) > 0 && req.UserID != userID {
+		log.Printf("User id mismatch: header=%q, message=%q", userID, req.UserID)
+		if len(userID) == 0 {
+			conn.WriteJSON(speech.NewErrorResponse(errno.ErrMissingHeader.WithFmt(config.HEADER_X_KSC_ACCOUNT_ID)))
+		} else {
+			conn.WriteJSON(speech.NewErrorResponse(*errno.ErrPermissionDenied))
+		}
+		return nil
+	}
+	req.UserID = userID
+
+	// 从内容库条目开始评测时，参考文本、评测模式和引擎类型以条目为准
+	if len(req.ItemID) > 0 {
+		item, err := content.TenantItem(middleware.TenantName(c.Request()), req.ItemID)
+		if err != nil {
+			log.Printf("Load item error: %v", err)
+			conn.WriteJSON(speech.NewErrorResponse(err))
+			return nil
+		}
+		itemReq := item.AssessmentRequest()
+		req.RefText = itemReq.RefText
+		req.EvalMode = itemReq.EvalMode
+		req.ServerEngineType = itemReq.ServerEngineType
+	}
+
+	// 确定会话所在区域，租户限定了区域时不能评测到其他区域
+	region, err := cfg.ResolveRegion(req.Region, tenant)
+	if err != nil {
+		log.Printf("Resolve region error: %v", err)
+		conn.WriteJSON(speech.NewErrorResponse(err))
+		return nil
+	}
+	req.Region = region.Name
+
+	// 其他区域的学习者数据不能保存在本实例：不保存录音和评测记录，也不能提交作业
+	resident := cfg.Resident(region)
+	if !resident {
+		if len(req.AssignmentID) > 0 {
+			log.Printf("Assignment submission rejected in non-resident region %s", region.Name)
+			conn.WriteJSON(speech.NewErrorResponse(*errno.ErrUnsupportedRegion))
+			return nil
+		}
+		req.IsSaveAudioFile = false
+	}
+
+	// 作业提交需要是班级成员，且条目属于该作业
+	var assignment *classroom.Assignment
+	if len(req.AssignmentID) > 0 {
+		if len(req.UserID) == 0 {
+			conn.WriteJSON(speech.NewErrorResponse(errno.ErrMissingHeader.WithFmt(config.HEADER_X_KSC_ACCOUNT_ID)))
+			return nil
+		}
+		if assignment, err = classroom.CheckSubmission(middleware.TenantName(c.Request()), req.AssignmentID, req.UserID, req.ItemID); err != nil {
+			log.Printf("Invalid submission: %v", err)
+			conn.WriteJSON(speech.NewErrorResponse(err))
+			return nil
+		}
+	}
+
+	// 评分配置由服务端决定：考试模式使用作业指定的配置，
+	// 否则只能选择租户允许的配置，未选择时使用租户或服务端默认配置
+	exam := assignment != nil && assignment.Exam != nil
+	if exam {
+		req.ScoringProfile = assignment.Exam.ScoringProfile
+	} else if len(req.ScoringProfile) > 0 && !tenant.AllowScoringProfile(req.ScoringProfile) {
+		log.Printf("Scoring profile not allowed: %q", req.ScoringProfile)
+		conn.WriteJSON(speech.NewErrorResponse(errno.ErrInvalidParameterValue.WithFmt(
+			fmt.Sprintf("The scoring profile %q is not allowed.", req.ScoringProfile))))
+		return nil
+	}
+
+	// 客户端未指定评测模式时，根据参考文本自动检测
+	if req.EvalMode == speech.EvalModeAuto {
+		mode := speech.DetectMode(req.RefText)
+		req.EvalMode = mode.EvalMode()
+		if len(req.ServerEngineType) == 0 {
+			req.ServerEngineType = mode.EngineType()
+		}
+		log.Printf("自动检测评测模式: Mode=%d, EvalMode=%d", mode, req.EvalMode)
+	}
+
+	// 租户的默认引擎和评分配置
+	if tenant != nil {
+		req.Tenant = tenant.Name
+		if len(req.ServerEngineType) == 0 {
+			req.ServerEngineType = tenant.DefaultEngineType
+		}
+		if len(req.ScoringProfile) == 0 && !exam {
+			req.ScoringProfile = tenant.ScoringProfile
+		}
+	}
+
+	req.FillDefault()
+
+	// 评分系数由服务端评分配置决定
+	scorer, err := cfg.Scoring.Lookup(req.ScoringProfile)
+	if err != nil {
+		log.Printf("Invalid config: %v", err)
+		conn.WriteJSON(speech.NewErrorResponse(err))
+		return nil
+	}
+	req.ScoreCoeff = scorer.Profile.ScoreCoeff
+
+	// 校验参数，避免无效请求发送到上游
+	if err = req.Validator(); err != nil {
+		log.Printf("Invalid config: %v", err)
+		conn.WriteJSON(speech.NewErrorResponse(err))
+		return nil
+	}
+
+	// 考试模式检查时间窗口和剩余次数，参数校验通过后才计入次数，上游启动失败时撤销
+	if exam {
+		attempt, err := classroom.StartAttempt(assignment, req.UserID, req.ItemID, time.Now())
+		if err != nil {
+			log.Printf("Exam attempt rejected: %v", err)
+			conn.WriteJSON(speech.NewErrorResponse(err))
+			return nil
+		}
+		log.Printf("考试模式: Assignment=%s, User=%s, Item=%s, Attempt=%d",
+			assignment.ID, req.UserID, req.ItemID, attempt)
+	}
+
+	log.Printf("收到配置: RefText=%s, EngineType=%s, EvalMode=%d, ScoringProfile=%s, ScoreCoeff=%.2f, Region=%s",
+		req.RefText, req.ServerEngineType, req.EvalMode, scorer.Name, req.ScoreCoeff, req.Region)
+
+	// 创建评测会话，参考文本过长时自动分段，主引擎失败时切换到降级引擎
+	session := speech.NewSession(conn, &req, sessionEngines(cfg, tenant, region, &req))
+	session.Scorer = scorer
+	session.Feedback = cfg.Feedback.Lookup(req.Locale)
+	session.Exam = exam
+	session.Upstream = cfg.Upstream
+	if resident {
+		session.OnComplete = completeSession
+	}
+
+	// 学生所在班级的教师可以实时查看评测过程
+	if classIDs := classroom.MemberClasses(req.Tenant, req.UserID); len(classIDs) > 0 {
+		session.OnResponse = func(s *speech.Session, response speech.AssessmentResponse) {
+			monitor.Publish(classIDs, s, response)
+		}
+		defer monitor.End(classIDs, session.ID)
+	}
+
+	// 启动识别器
+	log.Println("准备启动识别器...")
+	if err = session.Start(); err != nil {
+		log.Printf("Recognizer start error: %v", err)
+		if exam {
+			if err := classroom.CancelAttempt(assignment, req.UserID, req.ItemID); err != nil {
+				log.Printf("Cancel exam attempt error: %v", err)
+			}
+		}
+		conn.WriteJSON(speech.NewErrorResponse(err))
+		return nil
+	}
+	log.Println("识别器已成功启动")
+
+	// 确保识别器在结束时停止
+	defer func() {
+		log.Println("正在停止识别器...")
+		session.Close()
+		log.Println("识别器已停止")
+	}()
+
+	// 创建音频文件用于调试
+	var audioFile *os.File
+	if req.IsSaveAudioFile {
+		fileName := generateUniqueFilename(mimeType)
+		audioFile, err := os.Create(fileName)
+		if err != nil {
+			log.Printf("创建音频文件失败: %v", err)
+		} else {
+			defer audioFile.Close()
+			log.Printf("音频将保存到: %s", fileName)
+		}
+	}
+
+	var (
+		totalBytes  int
+		startTime   = time.Now()
+		audioChunks [][]byte
+	)
+
+	// 处理WebSocket消息
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		defer log.Println("音频处理协程已退出")
+
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+					log.Printf("WebSocket read error: %v", err)
+				}
+				session.Fail(err)
+				return
+			}
+
+			// 处理结束标志
+			if messageType == websocket.TextMessage {
+				var endMsg EndMessage
+				if json.Unmarshal(message, &endMsg) == nil && endMsg.Type == "end" {
+					log.Println("收到客户端结束标志")
+
+					// 计算音频时长
+					duration := req.AudioFormat().Duration(totalBytes)
+					log.Printf("音频接收完成: Total=%dByte, Estimated duration=%.2fs, cost=%.2fs",
+						totalBytes, duration, time.Since(startTime).Seconds())
+
+					// 主动通知SDK音频传输结束
+					log.Println("通知识别器音频传输结束")
+					session.Finish()
+
+					return
+				}
+				// 忽略其他文本消息
+				continue
+			}
+
+			// 只处理二进制消息
+			if messageType != websocket.BinaryMessage {
+				continue
+			}
+
+			// 记录音频数据
+			totalBytes += len(message)
+			audioChunks = append(audioChunks, message)
+
+			if req.IsSaveAudioFile {
+				// 写入文件（用于调试）
+				if audioFile != nil {
+					if _, err := audioFile.Write(message); err != nil {
+						log.Printf("写入音频文件失败: %v", err)
+					}
+				}
+			}
+
+			// 发送音频数据到识别器
+			log.Printf("发送音频块到识别器: Size=%dByte, Total=%dByte", len(message), totalBytes)
+			if err := session.Write(message); err != nil {
+				log.Printf("Recognizer write error: %v", err)
+				session.Fail(err)
+				return
+			}
+		}
+	}()
+
+	// 监听结果
+	select {
+	case err := <-session.ErrorChan:
+		log.Printf("Assessment error: %v", err)
+		return nil
+
+	case <-session.Complete:
+		log.Println("识别器流程已完整结束: Assessment completed")
+		wg.Wait()
+		return nil
+	}
+}
+
+// checkOrigin 租户配置了允许的来源时已由 ResolveTenant 校验，否则只允许同源页面；
+// 不带 Origin 的是非浏览器客户端，不受同源限制
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	if tenant := middleware.CurrentTenant(r); tenant != nil && len(tenant.AllowedOrigins) > 0 {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// completeSession 最终结果发送给客户端后异步完成防作弊检测，再保存评测记录，并更新作业提交和用户的复习计划
+func completeSession(s *speech.Session, result *speech.SOEResult) {
+	// 最终结果正在发送给客户端，检测结果只写入记录中的副本
+	copied := *result
+	samples, format := s.Samples()
+	go saveSession(s, &copied, samples, format.SampleRate)
+}
+
+// saveSession 检查录音是否为回放或与他人录音重复，检测结果随评测记录保存
+func saveSession(s *speech.Session, result *speech.SOEResult, samples []int16, sampleRate int) {
+	r := record.FromSession(s, result)
+
+	report, err := anticheat.Check(s.ID, r.Tenant, r.UserID, r.RefText, samples, sampleRate)
+	if err != nil {
+		log.Printf("保存录音指纹失败: %v", err)
+	}
+	r.AntiCheat = report
+	result.Suspicious = report.Suspicious
+	result.SuspiciousReasons = report.Reasons
+	if report.Suspicious {
+		log.Printf("录音疑似作弊: Session=%s, User=%s, Reasons=%v, Matched=%s, Similarity=%.2f",
+			s.ID, r.UserID, report.Reasons, report.MatchedSession, report.Similarity)
+	}
+
+	if err := record.Save(r); err != nil {
+		log.Printf("保存评测记录失败: %v", err)
+	}
+
+	if err := classroom.Submit(r.AssignmentID, r); err != nil {
+		log.Printf("更新作业提交失败: %v", err)
+	}
+
+	if err := review.Update(r.UserID, r); err != nil {
+		log.Printf("更新复习计划失败: %v", err)
+	}
+}
+
+// newRecognizer 使用会话开始时的配置创建识别器，配置重新加载后只影响新会话
+// proxyURL 为区域的出口代理。
+func newRecognizer(conf config.TencentCloudSpeechConfig, proxyURL string) speech.RecognizerFactory {
+	return func(req *speech.AssessmentRequest, listener *speech.StreamListener) (speech.Recognizer, error) {
+		recognizer := soe.NewSpeechRecognizer(conf.AppID, conf.Credentials(), listener)
+		recognizer.VoiceFormat = soe.AudioFormatWav
+		recognizer.RefText = req.RefText
+		recognizer.ServerEngineType = req.ServerEngineType
+		recognizer.ScoreCoeff = req.ScoreCoeff // 使用评分配置中的系数
+		recognizer.EvalMode = req.EvalMode
+		recognizer.TextMode = req.TextMode
+		recognizer.ProxyURL = proxyURL
+
+		return recognizer, nil
+	}
+}
+
+// sessionEngines 主引擎使用会话的账号和请求的引擎类型，之后依次为与请求语言相同的降级引擎
+// 主引擎按区域使用独立的熔断器，降级引擎各自使用独立的熔断器。
+// 租户会话和使用区域账号或代理的会话不使用有独立账号或代理的降级引擎，避免绕过租户计费和区域数据驻留。
+func sessionEngines(cfg *config.LingoLiftConfig, tenant *config.Tenant, region *config.Region, req *speech.AssessmentRequest) []*speech.Engine {
+	conf := cfg.SpeechConfig(tenant, region)
+	breaker := region.Name
+	if len(breaker) == 0 {
+		breaker = "default"
+	}
+	engines := []*speech.Engine{{
+		Name:          config.PrimaryEngine,
+		NewRecognizer: newRecognizer(conf, region.ProxyURL),
+		Breaker:       upstream.Get(breaker, cfg.Upstream.Breaker),
+	}}
+
+	dedicated := tenant != nil || region.Speech != nil || len(region.ProxyURL) > 0
+	language, _ := speech.EngineLanguage(req.ServerEngineType)
+	for _, e := range cfg.Fallback.Engines {
+		if dedicated && !e.Shared() {
+			continue
+		}
+		if len(e.ServerEngineType) > 0 {
+			if l, _ := speech.EngineLanguage(e.ServerEngineType); l != language {
+				continue
+			}
+		}
+
+		fallbackConf, proxyURL := conf, region.ProxyURL
+		if e.Speech != nil {
+			fallbackConf = *e.Speech
+		}
+		if len(e.ProxyURL) > 0 {
+			proxyURL = e.ProxyURL
+		}
+		engines = append(engines, &speech.Engine{
+			Name:             e.Name,
+			ServerEngineType: e.ServerEngineType,
+			NewRecognizer:    newRecognizer(fallbackConf, proxyURL),
+			Breaker:          upstream.Get("fallback/"+e.Name, cfg.Upstream.Breaker),
+		})
+	}
+	return engines
+}
+
+// 生成唯一文件名
+func generateUniqueFilename(mimeType string) string {
+	timestamp := time.Now().Format("20060102150405")
+	return fmt.Sprintf("audio_%s.wav", timestamp)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"lingolift/api"
+	"lingolift/config"
+
+	"github.com/labstack/echo/v4"
+)
+
+type tenantKey struct{}
+
+// ResolveTenant 按 API Key 确定租户并保存到请求上下文，未配置租户时不做检查
+func ResolveTenant() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tenant, err := config.Current().Tenants.Resolve(c.Request())
+			if err != nil {
+				return api.ReturnErr(c, err)
+			}
+			if tenant != nil {
+				c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), tenantKey{}, tenant)))
+			}
+			return next(c)
+		}
+	}
+}
+
+// CurrentTenant 返回 ResolveTenant 确定的租户，未配置租户时返回 nil
+func CurrentTenant(r *http.Request) *config.Tenant {
+	tenant, _ := r.Context().Value(tenantKey{}).(*config.Tenant)
+	return tenant
+}
+
+// TenantName 租户名称，未配置租户时为空
+func TenantName(r *http.Request) string {
+	if tenant := CurrentTenant(r); tenant != nil {
+		return tenant.Name
+	}
+	return ""
+}
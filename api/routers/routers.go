@@ -1,17 +1,55 @@
-package routers
-
-import (
-	"lingolift/api/handler"
-
-	"github.com/labstack/echo/v4"
-)
-
-func Load(e *echo.Echo) *echo.Echo {
-
-	e.Static("/", "public")
-
-	e.GET("/health", handler.Health)
-	e.GET("/ws/assessment", handler.StreamAssessment)
-
-	return e
-}
+package routers
+
+import (
+	"lingolift/api/handler"
+	"lingolift/api/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func Load(e *echo.Echo) *echo.Echo {
+
+	e.Static("/", "public")
+
+	e.GET("/health", handler.Health)
+
+	// 除健康检查和静态页面外，所有接口按 API Key 确定租户，数据只在租户内可见
+	ws := e.Group("/ws", middleware.ResolveTenant())
+	ws.GET("/assessment", handler.StreamAssessment)
+	ws.GET("/mic-check", handler.MicCheck)
+	ws.GET("/monitor", handler.Monitor)
+
+	v1 := e.Group("/v1", middleware.ResolveTenant())
+	v1.GET("/sessions/compare", handler.CompareSessions)
+	v1.GET("/sessions/:id/verify", handler.VerifySession)
+
+	v1.GET("/lessons", handler.ListLessons)
+	v1.POST("/lessons", handler.SaveLesson)
+	v1.GET("/lessons/:id", handler.GetLesson)
+	v1.PUT("/lessons/:id", handler.SaveLesson)
+	v1.DELETE("/lessons/:id", handler.DeleteLesson)
+	v1.GET("/lessons/:lesson_id/items", handler.ListItems)
+	v1.POST("/lessons/:lesson_id/items", handler.SaveItem)
+	v1.GET("/lessons/:lesson_id/items/export", handler.ExportItems)
+	v1.POST("/lessons/:lesson_id/items/import", handler.ImportItems)
+	v1.GET("/items/:id", handler.GetItem)
+	v1.PUT("/items/:id", handler.SaveItem)
+	v1.DELETE("/items/:id", handler.DeleteItem)
+
+	v1.GET("/users/:id/review", handler.ReviewQueue)
+
+	v1.GET("/classes", handler.ListClasses)
+	v1.POST("/classes", handler.CreateClass)
+	v1.GET("/classes/:id", handler.GetClass)
+	v1.GET("/classes/:id/members", handler.ListMembers)
+	v1.POST("/classes/:id/members", handler.AddMember)
+	v1.DELETE("/classes/:id/members/:user_id", handler.RemoveMember)
+	v1.POST("/classes/:id/join", handler.JoinClass)
+	v1.GET("/invitations", handler.ListInvitations)
+	v1.GET("/classes/:id/assignments", handler.ListAssignments)
+	v1.POST("/classes/:id/assignments", handler.CreateAssignment)
+	v1.GET("/assignments/:id/submissions", handler.ListSubmissions)
+	v1.GET("/assignments/:id/grades", handler.ExportGrades)
+
+	return e
+}
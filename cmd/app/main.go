@@ -1,71 +1,149 @@
-package main
-
-import (
-	"fmt"
-	"os"
-
-	"lingolift/config"
-	"lingolift/job"
-	"lingolift/pkg/log"
-	"lingolift/server"
-
-	"github.com/alecthomas/kingpin"
-	"github.com/prometheus/common/version"
-	"go.uber.org/zap"
-)
-
-// initLibraries 初始化库
-func initLibraries(cfg *config.LingoLiftConfig, logger *zap.Logger) (err error) {
-	go job.HealthCheck()
-	return
-}
-
-func main() {
-	var (
-		listenAddress = kingpin.Flag(
-			"web.listen-address",
-			"Address on which to expose metrics and web interface.",
-		).Default("").String()
-		metricsPath = kingpin.Flag(
-			"web.telemetry-path",
-			"Path under which to expose metrics.",
-		).Default("/metrics").String()
-		enableExporterMetrics = kingpin.Flag(
-			"web.enable-exporter-metrics",
-			"Include metrics about the exporter itself (http_*, process_*, go_*).",
-		).Default("true").Bool()
-		configFile = kingpin.Flag(
-			"config.file", "Kingsoft cloud monitor openapi configuration file.",
-		).Default("cfg.yml").String()
-	)
-
-	kingpin.Version(version.Print("monitor-openapi"))
-	kingpin.HelpFlag.Short('h')
-	kingpin.Parse()
-
-	openAPIConf := config.NewConfig()
-	if err := openAPIConf.LoadFile(*configFile); err != nil {
-		fmt.Fprintln(os.Stderr, "Unable to load configuration file:", configFile, err)
-		os.Exit(1)
-	}
-
-	openAPIConf.App.EnableExporterMetrics = *enableExporterMetrics
-	openAPIConf.App.MetricsPath = *metricsPath
-
-	// Initialize the service application log instance.
-	config.AppLogger = log.NewLogger(func(option *log.Options) {
-		option.LogFileDir = openAPIConf.App.Log.LogFileDir
-		option.AppName = "app"
-	})
-
-	config.AppLogger.Info(`Load configuration file successfully.`, zap.String(`service`, `monitor-openapi`))
-
-	if err := initLibraries(openAPIConf, config.AppLogger); err != nil {
-		fmt.Fprintln(os.Stderr, "Failed to init library:", *configFile, err)
-		os.Exit(1)
-	}
-
-	server.NewHTTPServerWithConfig(openAPIConf.App, config.AppLogger, *listenAddress)
-
-	os.Exit(0)
-}
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"lingolift/config"
+	"lingolift/job"
+	"lingolift/pkg/anticheat"
+	"lingolift/pkg/classroom"
+	"lingolift/pkg/content"
+	"lingolift/pkg/log"
+	"lingolift/pkg/record"
+	"lingolift/pkg/review"
+	"lingolift/pkg/store"
+	"lingolift/server"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/prometheus/common/version"
+	"go.uber.org/zap"
+)
+
+// initLibraries 初始化库
+func initLibraries(cfg *config.LingoLiftConfig, logger *zap.Logger) (err error) {
+	db, err := store.Open(cfg.Store)
+	if err != nil {
+		return err
+	}
+
+	if err = record.Init(db, cfg.Record); err != nil {
+		return err
+	}
+
+	if err = content.Init(db); err != nil {
+		return err
+	}
+
+	if err = review.Init(db); err != nil {
+		return err
+	}
+
+	if err = classroom.Init(db); err != nil {
+		return err
+	}
+
+	if err = anticheat.Init(db); err != nil {
+		return err
+	}
+
+	go job.HealthCheck()
+	return
+}
+
+func main() {
+	// 命令行设置的配置项，优先级高于配置文件和环境变量
+	var overrides []config.Override
+	override := func(flag, key string, value *string) kingpin.Action {
+		return func(*kingpin.ParseContext) error {
+			overrides = append(overrides, config.Override{Key: key, Value: *value, Flag: "--" + flag})
+			return nil
+		}
+	}
+
+	var (
+		listenAddress         string
+		metricsPath           string
+		enableExporterMetrics string
+		configFile            = kingpin.Flag(
+			"config.file", "Kingsoft cloud monitor openapi configuration file.",
+		).Default("cfg.yml").String()
+		printConfig = kingpin.Flag(
+			"print-config", "Print the effective configuration with secrets masked and the source of each overridden value, then exit.",
+		).Default("false").Bool()
+	)
+	kingpin.Flag(
+		"web.listen-address",
+		"Address on which to expose metrics and web interface.",
+	).Action(override("web.listen-address", "app_conf.http_conf.address", &listenAddress)).StringVar(&listenAddress)
+	kingpin.Flag(
+		"web.telemetry-path",
+		"Path under which to expose metrics.",
+	).Action(override("web.telemetry-path", "app_conf.metrics_path", &metricsPath)).StringVar(&metricsPath)
+	kingpin.Flag(
+		"web.enable-exporter-metrics",
+		"Include metrics about the exporter itself (http_*, process_*, go_*).",
+	).Action(override("web.enable-exporter-metrics", "app_conf.enable_exporter_metrics", &enableExporterMetrics)).StringVar(&enableExporterMetrics)
+	settings := kingpin.Flag(
+		"set", "Set a config value by its YAML path, e.g. --set app_conf.http_conf.address=:9090. Repeatable.",
+	).StringMap()
+
+	kingpin.Version(version.Print("monitor-openapi"))
+	kingpin.HelpFlag.Short('h')
+	kingpin.Parse()
+
+	keys := make([]string, 0, len(*settings))
+	for key := range *settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		overrides = append(overrides, config.Override{Key: key, Value: (*settings)[key], Flag: "--set"})
+	}
+
+	openAPIConf := config.NewConfig()
+	if err := openAPIConf.LoadFile(*configFile, overrides...); err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to load configuration file:", *configFile, err)
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		fmt.Print(openAPIConf)
+		for _, source := range openAPIConf.Sources() {
+			if source.Source != config.SourceDefault {
+				fmt.Println("#", source)
+			}
+		}
+		os.Exit(0)
+	}
+
+	// Initialize the service application log instance.
+	config.AppLogger = log.NewLogger(func(option *log.Options) {
+		option.LogFileDir = openAPIConf.App.Log.LogFileDir
+		option.AppName = "app"
+		option.Level = openAPIConf.App.Log.Level
+	})
+
+	config.AppLogger.Info(`Load configuration file successfully.`, zap.String(`service`, `monitor-openapi`))
+
+	if err := initLibraries(openAPIConf, config.AppLogger); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to init library:", *configFile, err)
+		os.Exit(1)
+	}
+
+	// 配置文件变化或收到 SIGHUP 时重新加载，日志等级立即生效，认证信息只用于新会话
+	reloader := config.NewReloader(*configFile, overrides, time.Duration(openAPIConf.App.ReloadInterval)*time.Second)
+	reloader.OnReload(func(prev, next *config.LingoLiftConfig) {
+		log.ChangeLevel(next.App.Log.Level)
+	})
+	go reloader.Run()
+
+	// 定期重新读取密钥引用（file://、keystore://、vault://），轮换密钥后无需重启
+	go config.RefreshSecrets()
+
+	server.NewHTTPServerWithConfig(openAPIConf.App, config.AppLogger)
+
+	os.Exit(0)
+}
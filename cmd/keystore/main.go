@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"lingolift/pkg/secret"
+
+	"github.com/alecthomas/kingpin"
+)
+
+// keystore 管理加密的本地密钥库，配置中使用 keystore://<name> 引用其中的密钥
+//
+//	keystore --key-file=master.key genkey
+//	keystore --key-file=master.key --file=secrets.ks set secret_key    # 省略值时从标准输入读取，避免留在 shell 历史中
+//	keystore --key-file=master.key --file=secrets.ks list
+//	keystore --key-file=master.key --file=secrets.ks delete secret_key
+func main() {
+	var (
+		keyFile = kingpin.Flag("key-file", "Master key file (base64 encoded 32 bytes).").Required().String()
+		file    = kingpin.Flag("file", "Keystore file.").Default("secrets.ks").String()
+
+		genkey = kingpin.Command("genkey", "Generate a new master key into --key-file.")
+
+		set      = kingpin.Command("set", "Add or replace a secret.")
+		setName  = set.Arg("name", "Secret name.").Required().String()
+		setValue = set.Arg("value", "Secret value, read from stdin when omitted.").String()
+
+		list = kingpin.Command("list", "List secret names.")
+
+		del     = kingpin.Command("delete", "Delete a secret.")
+		delName = del.Arg("name", "Secret name.").Required().String()
+	)
+
+	kingpin.HelpFlag.Short('h')
+	command := kingpin.Parse()
+
+	if command == genkey.FullCommand() {
+		if _, err := os.Stat(*keyFile); err == nil {
+			exit(fmt.Errorf("%s already exists", *keyFile))
+		}
+		exit(os.WriteFile(*keyFile, []byte(secret.GenerateKey()+"\n"), 0o600))
+	}
+
+	key, err := secret.ReadKey(*keyFile)
+	if err != nil {
+		exit(err)
+	}
+	secrets, err := secret.LoadKeystore(*file, key)
+	if err != nil {
+		exit(err)
+	}
+
+	switch command {
+	case set.FullCommand():
+		value := *setValue
+		if len(value) == 0 {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && len(line) == 0 {
+				exit(err)
+			}
+			value = strings.TrimRight(line, "\r\n")
+		}
+		secrets[*setName] = value
+		exit(secret.SaveKeystore(*file, key, secrets))
+
+	case list.FullCommand():
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+
+	case del.FullCommand():
+		if _, ok := secrets[*delName]; !ok {
+			exit(fmt.Errorf("secret %q not found", *delName))
+		}
+		delete(secrets, *delName)
+		exit(secret.SaveKeystore(*file, key, secrets))
+	}
+}
+
+func exit(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -1,204 +1,341 @@
-package config
-
-import (
-	"errors"
-	"fmt"
-	"os"
-
-	"lingolift/pkg/log"
-
-	"github.com/toolkits/net"
-	"go.uber.org/zap"
-	"gopkg.in/yaml.v2"
-)
-
-var (
-	// G Global configuration instance
-	G *LingoLiftConfig
-
-	// AppLogger is used for recording the logs of the application.
-	// including (startup logs, exception logs, job logs).
-	AppLogger *zap.Logger
-
-	// AccessLogger HTTP access logs
-	AccessLogger *zap.Logger
-
-	ServerNodeIP = ""
-)
-
-// LingoLiftConfig
-type LingoLiftConfig struct {
-	Filename string     `yaml:"filename"`
-	App      *AppConfig `yaml:"app_conf"`
-
-	Speech TencentCloudSpeechConfig `yaml:"tencent_speech_conf"`
-}
-
-// NewConfig
-func NewConfig() *LingoLiftConfig {
-	return &LingoLiftConfig{}
-}
-
-// LoadFile
-func (c *LingoLiftConfig) LoadFile(filename string) error {
-	c.Filename = filename
-
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return err
-	}
-
-	if err = yaml.UnmarshalStrict(content, c); err != nil {
-		return err
-	}
-
-	if err = c.check(); err != nil {
-		return err
-	}
-
-	c.fillDefault()
-
-	if err = c.Speech.check(); err != nil {
-		return err
-	}
-
-	G = c
-
-	fmt.Println(G.Speech)
-
-	return nil
-}
-
-// check
-func (c *LingoLiftConfig) check() error {
-	//检查服务基本配置是否正确
-	if err := c.App.Check(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// fillDefault
-func (c *LingoLiftConfig) fillDefault() {
-	ServerNodeIP = c.App.ServerIP
-}
-
-// AppConfig
-type AppConfig struct {
-	// Server listening IP, auto get intranet IP when empty
-	ServerIP string `yaml:"server_ip"`
-
-	// Region identifier for multi-region deployment
-	Region string `yaml:"region"`
-
-	// HTTP server configuration (timeouts, address etc.)
-	HTTP *HTTPServerConfig `yaml:"http_conf"`
-
-	// Log configuration options (output path, log level etc.)
-	Log *log.Options `yaml:"log_conf"`
-
-	// Enable Prometheus exporter metrics collection
-	EnableExporterMetrics bool `yaml:"enable_exporter_metrics"`
-
-	// Metrics exposure path, default /metrics
-	MetricsPath string `yaml:"metrics_path"`
-
-	// Enable pprof performance analysis endpoints
-	EnablePProf bool `yaml:"enable_pprof"`
-}
-
-// check 检查基础配置
-func (c *AppConfig) Check() error {
-	if len(c.ServerIP) <= 0 {
-		if err := c.ParseLocalServeIntranetIP(); err != nil {
-			return err
-		}
-	}
-
-	c.HTTP.fillDefault()
-
-	return nil
-}
-
-// ParseLocalServeIntranetIP
-func (c *AppConfig) ParseLocalServeIntranetIP() error {
-	ips, err := net.IntranetIP()
-	if err != nil {
-		return fmt.Errorf("failed to get intranet IPs: %w", err)
-	}
-
-	if len(ips) == 0 {
-		return errors.New("not resolve to the intranet IP.")
-	}
-
-	c.ServerIP = ips[0]
-
-	return nil
-}
-
-// HTTPServerConfig
-type HTTPServerConfig struct {
-	Address        string `yaml:"address"`
-	IdleTimeout    int    `yaml:"idle_timeout"`
-	ReadTimeout    int    `yaml:"read_timeout"`
-	WriteTimeout   int    `yaml:"write_timeout"`
-	MaxHeaderBytes int    `yaml:"max_header_bytes"`
-}
-
-// checkout
-func (c *HTTPServerConfig) fillDefault() {
-	if c.IdleTimeout <= 0 {
-		c.IdleTimeout = 60
-	}
-
-	if c.ReadTimeout <= 0 {
-		c.ReadTimeout = 15
-	}
-
-	if c.WriteTimeout <= 0 {
-		c.WriteTimeout = 60
-	}
-}
-
-// TencentCloudSpeechConfig
-type TencentCloudSpeechConfig struct {
-	AppID     string `yaml:"app_id"`
-	SecretID  string `yaml:"secret_id"`
-	SecretKey string `yaml:"secret_key"`
-	Token     string `yaml:"token"`
-	SliceSize int    `yaml:"slice_size"`
-}
-
-// check
-func (c *TencentCloudSpeechConfig) check() error {
-	if len(c.AppID) <= 0 {
-		appId := os.Getenv("app_id")
-		if len(appId) <= 0 {
-			return errors.New("app_id is empty")
-		}
-		c.AppID = appId
-	}
-
-	if len(c.SecretID) <= 0 {
-		secretId := os.Getenv("secret_id")
-		if len(secretId) <= 0 {
-			return errors.New("secret_id is empty")
-		}
-		c.SecretID = secretId
-	}
-
-	if len(c.SecretKey) <= 0 {
-		secretKey := os.Getenv("secret_key")
-		if len(secretKey) <= 0 {
-			return errors.New("secret_key is empty")
-		}
-		c.SecretKey = secretKey
-	}
-
-	if c.SliceSize <= 0 {
-		c.SliceSize = 2000
-	}
-
-	return nil
-}
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"lingolift/pkg/log"
+	"lingolift/pkg/record"
+	"lingolift/pkg/secret"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/store"
+	"lingolift/pkg/upstream"
+
+	"github.com/toolkits/net"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// AppLogger is used for recording the logs of the application.
+	// including (startup logs, exception logs, job logs).
+	AppLogger *zap.Logger
+
+	// AccessLogger HTTP access logs
+	AccessLogger *zap.Logger
+
+	ServerNodeIP = ""
+)
+
+// LingoLiftConfig
+type LingoLiftConfig struct {
+	Filename string     `yaml:"-"`
+	App      *AppConfig `yaml:"app_conf"`
+
+	Speech TencentCloudSpeechConfig `yaml:"tencent_speech_conf"`
+
+	// Scoring 服务端评分配置，客户端只能按名称选择
+	Scoring *speech.ScoringConfig `yaml:"scoring_conf"`
+
+	// Feedback 文字反馈模板，按语言存放在模板目录下
+	Feedback *speech.FeedbackConfig `yaml:"feedback_conf"`
+
+	// Store 评测记录等业务数据的存储配置
+	Store *store.Options `yaml:"store_conf"`
+
+	// Record 评测记录配置（考试记录签名密钥）
+	Record *record.Options `yaml:"record_conf"`
+
+	// Tenants 租户（学校）配置，每个租户使用独立的语音服务账号
+	Tenants *TenantsConfig `yaml:"tenants_conf"`
+
+	// Regions 多区域路由，每个区域可以使用独立的语音服务账号和出口代理
+	Regions *RegionsConfig `yaml:"regions_conf"`
+
+	// Fallback 主引擎失败时依次切换的降级引擎
+	Fallback *FallbackConfig `yaml:"fallback_conf"`
+
+	// Upstream 连接语音服务的超时、重试和熔断配置
+	Upstream *upstream.Options `yaml:"upstream_conf"`
+
+	// Secrets 密钥来源，语音服务的认证信息可以使用 file://、keystore://、vault:// 引用
+	Secrets *secret.Options `yaml:"secrets_conf"`
+
+	// sources 每个配置项的生效来源
+	sources map[string]Source
+
+	resolver *secret.Resolver
+}
+
+// NewConfig
+func NewConfig() *LingoLiftConfig {
+	return &LingoLiftConfig{
+		App: &AppConfig{
+			EnableExporterMetrics: true,
+			MetricsPath:           "/metrics",
+		},
+		Upstream: &upstream.Options{
+			MaxRetries: 2,
+		},
+	}
+}
+
+// LoadFile 加载配置并设为当前生效的配置
+func (c *LingoLiftConfig) LoadFile(filename string, overrides ...Override) error {
+	if err := c.load(filename, overrides); err != nil {
+		return err
+	}
+
+	current.Store(c)
+
+	return nil
+}
+
+// load 加载配置文件，再依次应用环境变量和命令行设置的配置项，并检查配置
+func (c *LingoLiftConfig) load(filename string, overrides []Override) error {
+	c.Filename = filename
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if err = yaml.UnmarshalStrict(content, c); err != nil {
+		return err
+	}
+
+	if err = c.layer(content, overrides); err != nil {
+		return err
+	}
+
+	if err = c.check(); err != nil {
+		return err
+	}
+
+	if err = c.resolveSecrets(); err != nil {
+		return err
+	}
+
+	// 所有租户、区域都使用独立账号时可以不配置全局账号
+	if c.usesGlobalSpeech() || len(c.Speech.AppID) > 0 {
+		if err = c.Speech.check(speechConfPath); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Regions.check(c.App.Region); err != nil {
+		return err
+	}
+
+	if err = c.Tenants.check(c.Scoring, c.Regions); err != nil {
+		return err
+	}
+
+	if err = c.Fallback.check(); err != nil {
+		return err
+	}
+
+	c.fillDefault()
+
+	return nil
+}
+
+// check
+func (c *LingoLiftConfig) check() error {
+	//检查服务基本配置是否正确
+	if err := c.App.Check(); err != nil {
+		return err
+	}
+
+	if c.Scoring == nil {
+		c.Scoring = &speech.ScoringConfig{}
+	}
+	if err := c.Scoring.Check(); err != nil {
+		return err
+	}
+
+	if c.Feedback == nil {
+		c.Feedback = &speech.FeedbackConfig{}
+	}
+	if err := c.Feedback.Load(); err != nil {
+		return err
+	}
+
+	if c.Upstream == nil {
+		c.Upstream = &upstream.Options{}
+	}
+	if err := c.Upstream.Check(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// usesGlobalSpeech 是否有会话会使用全局的语音服务账号
+func (c *LingoLiftConfig) usesGlobalSpeech() bool {
+	if !c.Tenants.Enabled() {
+		// 区域未单独配置账号时使用全局账号
+		for _, r := range c.Regions.Regions {
+			if r == nil || r.Speech == nil {
+				return true
+			}
+		}
+		return !c.Regions.Enabled()
+	}
+
+	for _, t := range c.Tenants.Tenants {
+		if t.Speech == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fillDefault
+func (c *LingoLiftConfig) fillDefault() {
+	ServerNodeIP = c.App.ServerIP
+}
+
+// AppConfig
+type AppConfig struct {
+	// Server listening IP, auto get intranet IP when empty
+	ServerIP string `yaml:"server_ip"`
+
+	// Region identifier for multi-region deployment
+	Region string `yaml:"region"`
+
+	// HTTP server configuration (timeouts, address etc.)
+	HTTP *HTTPServerConfig `yaml:"http_conf"`
+
+	// Log configuration options (output path, log level etc.)
+	Log *log.Options `yaml:"log_conf"`
+
+	// Enable Prometheus exporter metrics collection
+	EnableExporterMetrics bool `yaml:"enable_exporter_metrics"`
+
+	// Metrics exposure path, default /metrics
+	MetricsPath string `yaml:"metrics_path"`
+
+	// Enable pprof performance analysis endpoints
+	EnablePProf bool `yaml:"enable_pprof"`
+
+	// Interval in seconds to check the config file for changes, default 10, negative disables polling (SIGHUP still reloads)
+	ReloadInterval int `yaml:"reload_interval"`
+}
+
+// check 检查基础配置
+func (c *AppConfig) Check() error {
+	if len(c.ServerIP) <= 0 {
+		if err := c.ParseLocalServeIntranetIP(); err != nil {
+			return err
+		}
+	}
+
+	c.HTTP.fillDefault()
+
+	if c.ReloadInterval == 0 {
+		c.ReloadInterval = 10
+	}
+
+	return nil
+}
+
+// ParseLocalServeIntranetIP
+func (c *AppConfig) ParseLocalServeIntranetIP() error {
+	ips, err := net.IntranetIP()
+	if err != nil {
+		return fmt.Errorf("failed to get intranet IPs: %w", err)
+	}
+
+	if len(ips) == 0 {
+		return errors.New("not resolve to the intranet IP.")
+	}
+
+	c.ServerIP = ips[0]
+
+	return nil
+}
+
+// HTTPServerConfig
+type HTTPServerConfig struct {
+	Address        string `yaml:"address"`
+	IdleTimeout    int    `yaml:"idle_timeout"`
+	ReadTimeout    int    `yaml:"read_timeout"`
+	WriteTimeout   int    `yaml:"write_timeout"`
+	MaxHeaderBytes int    `yaml:"max_header_bytes"`
+}
+
+// checkout
+func (c *HTTPServerConfig) fillDefault() {
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = 60
+	}
+
+	if c.ReadTimeout <= 0 {
+		c.ReadTimeout = 15
+	}
+
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = 60
+	}
+}
+
+// TencentCloudSpeechConfig
+type TencentCloudSpeechConfig struct {
+	AppID     string `yaml:"app_id"`
+	SecretID  string `yaml:"secret_id"`
+	SecretKey string `yaml:"secret_key"`
+	Token     string `yaml:"token"`
+	SliceSize int    `yaml:"slice_size"`
+
+	// refs 使用密钥引用的字段，刷新时重新解析
+	refs map[string]string
+}
+
+// String 密钥脱敏后输出
+func (c TencentCloudSpeechConfig) String() string {
+	return fmt.Sprintf("{AppID:%s SecretID:%s SecretKey:%s Token:%s SliceSize:%d}",
+		c.AppID, log.Mask(c.SecretID), log.Mask(c.SecretKey), log.Mask(c.Token), c.SliceSize)
+}
+
+// MarshalLogObject 密钥脱敏后写入日志
+func (c TencentCloudSpeechConfig) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("app_id", c.AppID)
+	enc.AddString("secret_id", log.Mask(c.SecretID))
+	enc.AddString("secret_key", log.Mask(c.SecretKey))
+	enc.AddString("token", log.Mask(c.Token))
+	enc.AddInt("slice_size", c.SliceSize)
+	return nil
+}
+
+// check path 为账号的配置路径，用于错误信息
+func (c *TencentCloudSpeechConfig) check(path string) error {
+	// 评测 SDK 建立连接时只签名 secretid，不会发送 token，临时凭证会被服务端拒绝
+	if len(c.Token) > 0 {
+		return fmt.Errorf("%s.token is not supported: the speech SDK does not send the temporary token", path)
+	}
+
+	for _, f := range []struct{ key, value string }{
+		{"app_id", c.AppID},
+		{"secret_id", c.SecretID},
+		{"secret_key", c.SecretKey},
+	} {
+		if len(f.value) > 0 {
+			continue
+		}
+		key := path + "." + f.key
+		// 只有全局账号可以通过环境变量设置
+		if path == speechConfPath {
+			return fmt.Errorf("%s is empty, set it in the config file or %s", key, EnvName(key))
+		}
+		return fmt.Errorf("%s is empty, set it in the config file", key)
+	}
+
+	if c.SliceSize <= 0 {
+		c.SliceSize = 2000
+	}
+
+	return nil
+}
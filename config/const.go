@@ -1,12 +1,12 @@
-package config
-
-const (
-	DBMaster = "master"
-	DBSlave  = "slave"
-
-	HEADER_X_KSC_REQUEST_ID = "X-REQUEST-ID"
-	HEADER_X_KSC_ACCOUNT_ID = "X-USER-ID"
-	HEADER_X_KSC_TYPE       = "X-TYPE"
-	HEADER_X_KSC_REAL_IP    = "X-REAL-IP"
-	HEADER_X_ERROR_CODE     = "X-ERROR-CODE"
-)
+package config
+
+const (
+	DBMaster = "master"
+	DBSlave  = "slave"
+
+	HEADER_X_KSC_REQUEST_ID = "X-REQUEST-ID"
+	HEADER_X_KSC_ACCOUNT_ID = "X-USER-ID"
+	HEADER_X_KSC_TYPE       = "X-TYPE"
+	HEADER_X_KSC_REAL_IP    = "X-REAL-IP"
+	HEADER_X_ERROR_CODE     = "X-ERROR-CODE"
+)
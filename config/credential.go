@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/common"
+)
+
+// Credentials 返回创建识别器使用的认证信息
+// 评测 SDK 只签名 secretid、不发送 token，只能使用长期密钥。
+func (c *TencentCloudSpeechConfig) Credentials() *common.Credential {
+	return common.NewCredential(c.SecretID, c.SecretKey)
+}
+
+// speechConfPath 全局语音服务账号的配置路径
+const speechConfPath = "tencent_speech_conf"
+
+// speechConfigs 全局、各租户、各区域和降级引擎的语音服务账号，键为配置路径
+func (c *LingoLiftConfig) speechConfigs() map[string]*TencentCloudSpeechConfig {
+	configs := map[string]*TencentCloudSpeechConfig{speechConfPath: &c.Speech}
+	for name, t := range c.Tenants.Tenants {
+		if t != nil && t.Speech != nil {
+			configs["tenants_conf.tenants."+name+".speech"] = t.Speech
+		}
+	}
+	for name, r := range c.Regions.Regions {
+		if r != nil && r.Speech != nil {
+			configs["regions_conf.regions."+name+".speech"] = r.Speech
+		}
+	}
+	for _, e := range c.Fallback.Engines {
+		if e.Speech != nil {
+			configs["fallback_conf.engines."+e.Name+".speech"] = e.Speech
+		}
+	}
+	return configs
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+
+	"lingolift/pkg/speech"
+)
+
+// PrimaryEngine 会话主引擎的名称，随最终结果返回给客户端
+const PrimaryEngine = "primary"
+
+// FallbackConfig 评测引擎的降级链
+// 主引擎（会话的语音服务账号和请求的引擎类型）失败时，按顺序切换到下一个引擎并重放已接收的音频。
+type FallbackConfig struct {
+	Engines []*FallbackEngine `yaml:"engines"`
+}
+
+// FallbackEngine 降级使用的引擎
+type FallbackEngine struct {
+	Name string `yaml:"name"`
+
+	// Speech 语音服务账号，为空时使用会话的账号；配置后不用于租户会话和区域会话
+	Speech *TencentCloudSpeechConfig `yaml:"speech"`
+
+	// ServerEngineType 引擎类型，为空时使用请求的引擎类型；与请求的语言不同时跳过
+	ServerEngineType string `yaml:"server_engine_type"`
+
+	// ProxyURL 访问上游语音服务的出口代理，为空时使用会话所在区域的代理；配置后不用于租户会话和区域会话
+	ProxyURL string `yaml:"proxy_url"`
+}
+
+// Shared 是否使用会话的账号和代理，只有这样的引擎可以用于租户会话和区域会话
+func (e *FallbackEngine) Shared() bool {
+	return e.Speech == nil && len(e.ProxyURL) == 0
+}
+
+// check 检查降级引擎，名称不能重复
+func (c *FallbackConfig) check() error {
+	names := map[string]bool{}
+	for i, e := range c.Engines {
+		if e == nil || len(e.Name) == 0 {
+			return fmt.Errorf("fallback engine #%d: name is required", i)
+		}
+		if e.Name == PrimaryEngine {
+			return fmt.Errorf("fallback engine name %q is reserved", e.Name)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("fallback engine %q is duplicated", e.Name)
+		}
+		names[e.Name] = true
+
+		if len(e.ServerEngineType) > 0 {
+			if _, ok := speech.EngineLanguage(e.ServerEngineType); !ok {
+				return fmt.Errorf("fallback engine %q: server_engine_type %q is not supported", e.Name, e.ServerEngineType)
+			}
+		}
+		if e.Speech != nil {
+			if err := e.Speech.check("fallback_conf.engines." + e.Name + ".speech"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// clone 复制降级配置，用于刷新密钥时替换语音服务账号
+func (c *FallbackConfig) clone() *FallbackConfig {
+	next := &FallbackConfig{}
+	for _, e := range c.Engines {
+		copied := *e
+		if e.Speech != nil {
+			speech := *e.Speech
+			copied.Speech = &speech
+		}
+		next.Engines = append(next.Engines, &copied)
+	}
+	return next
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 配置分层：每个配置项都可以通过配置文件、环境变量和命令行设置，优先级从低到高为
+//
+//	默认值 < 配置文件 < 环境变量 < 命令行
+//
+// 配置项的键为 YAML 路径，例如 app_conf.http_conf.address；
+// 环境变量名为 LINGOLIFT_ 加上大写的路径，点号换成下划线，例如 LINGOLIFT_APP_CONF_HTTP_CONF_ADDRESS；
+// 命令行使用 --set app_conf.http_conf.address=:9090，可重复指定。
+// 字符串以外的值按 YAML 解析，列表、映射可以使用 [a, b]、{k: v} 的写法。
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "LINGOLIFT_"
+
+// 配置项来源
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// legacyEnv 兼容旧版本使用的小写环境变量，优先级低于 LINGOLIFT_ 前缀的变量
+var legacyEnv = map[string]string{
+	"tencent_speech_conf.app_id":     "app_id",
+	"tencent_speech_conf.secret_id":  "secret_id",
+	"tencent_speech_conf.secret_key": "secret_key",
+}
+
+// Override 命令行设置的配置项
+type Override struct {
+	Key   string
+	Value string
+	Flag  string // 来源的命令行参数，例如 --set、--web.listen-address
+}
+
+// Source 配置项的生效来源
+type Source struct {
+	Key    string `json:"key"`
+	Source string `json:"source"`
+	Name   string `json:"name,omitempty"` // 文件名、环境变量名或命令行参数
+}
+
+// String
+func (s Source) String() string {
+	if len(s.Name) == 0 {
+		return fmt.Sprintf("%s: %s", s.Key, s.Source)
+	}
+	return fmt.Sprintf("%s: %s %s", s.Key, s.Source, s.Name)
+}
+
+// EnvName 配置项对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Sources 返回所有配置项的生效来源，按字段定义顺序排列
+func (c *LingoLiftConfig) Sources() []Source {
+	var sources []Source
+	for _, f := range c.fields() {
+		source, ok := c.sources[f.key]
+		if !ok {
+			source = Source{Key: f.key, Source: SourceDefault}
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// layer 依次应用配置文件、环境变量和命令行，并记录每个配置项的来源
+func (c *LingoLiftConfig) layer(content []byte, overrides []Override) error {
+	var doc map[interface{}]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+
+	c.sources = map[string]Source{}
+	fields := c.fields()
+	index := map[string]configField{}
+	for _, f := range fields {
+		index[f.key] = f
+		if lookupYAML(doc, f.key) {
+			c.sources[f.key] = Source{Key: f.key, Source: SourceFile, Name: c.Filename}
+		}
+	}
+
+	for _, f := range fields {
+		for _, name := range []string{legacyEnv[f.key], EnvName(f.key)} {
+			value, ok := os.LookupEnv(name)
+			if len(name) == 0 || !ok || len(value) == 0 {
+				continue
+			}
+			if err := f.set(value); err != nil {
+				return fmt.Errorf("env %s: %w", name, err)
+			}
+			c.sources[f.key] = Source{Key: f.key, Source: SourceEnv, Name: name}
+		}
+	}
+
+	for _, o := range overrides {
+		f, ok := index[o.Key]
+		if !ok {
+			return fmt.Errorf("flag %s: unknown config key %q", o.Flag, o.Key)
+		}
+		if err := f.set(o.Value); err != nil {
+			return fmt.Errorf("flag %s: %w", o.Flag, err)
+		}
+		c.sources[f.key] = Source{Key: f.key, Source: SourceFlag, Name: o.Flag}
+	}
+
+	return nil
+}
+
+// configField 可单独设置的配置项
+type configField struct {
+	key   string
+	value reflect.Value
+}
+
+// set 字符串直接赋值，其他类型按 YAML 解析
+func (f configField) set(value string) error {
+	if f.value.Kind() == reflect.String && !unmarshaler(f.value.Type()) {
+		f.value.SetString(value)
+		return nil
+	}
+
+	if err := yaml.UnmarshalStrict([]byte(value), f.value.Addr().Interface()); err != nil {
+		return fmt.Errorf("%s: %w", f.key, err)
+	}
+	return nil
+}
+
+// fields 列出所有配置项，未配置的子结构会被创建
+func (c *LingoLiftConfig) fields() []configField {
+	var fields []configField
+	collectFields(reflect.ValueOf(c).Elem(), "", &fields)
+	return fields
+}
+
+func collectFields(v reflect.Value, prefix string, fields *[]configField) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = strings.ToLower(sf.Name)
+		}
+		key := prefix + name
+
+		fv := v.Field(i)
+		switch {
+		case unmarshaler(sf.Type):
+			*fields = append(*fields, configField{key: key, value: fv})
+		case sf.Type.Kind() == reflect.Struct:
+			collectFields(fv, key+".", fields)
+		case sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct:
+			if fv.IsNil() {
+				fv.Set(reflect.New(sf.Type.Elem()))
+			}
+			collectFields(fv.Elem(), key+".", fields)
+		default:
+			*fields = append(*fields, configField{key: key, value: fv})
+		}
+	}
+}
+
+var (
+	yamlUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unmarshaler 类型自行实现了解析，作为一个整体设置
+func unmarshaler(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(yamlUnmarshaler) || pt.Implements(textUnmarshaler)
+}
+
+// lookupYAML 配置文件中是否设置了该路径
+func lookupYAML(doc map[interface{}]interface{}, key string) bool {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := doc[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if doc, ok = value.(map[interface{}]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
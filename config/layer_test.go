@@ -0,0 +1,152 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testLayerFile = `
+app_conf:
+  http_conf:
+    address: ":8080"
+  metrics_path: /file
+tencent_speech_conf:
+  app_id: "100"
+upstream_conf:
+  max_retries: 3
+`
+
+// layerConfig 解析配置文件后应用环境变量和命令行
+func layerConfig(t *testing.T, overrides ...Override) (*LingoLiftConfig, error) {
+	t.Helper()
+	c := NewConfig()
+	c.Filename = "cfg.yml"
+	if err := yaml.UnmarshalStrict([]byte(testLayerFile), c); err != nil {
+		t.Fatal(err)
+	}
+	return c, c.layer([]byte(testLayerFile), overrides)
+}
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"app_conf.http_conf.address", "LINGOLIFT_APP_CONF_HTTP_CONF_ADDRESS"},
+		{"store_conf.dir", "LINGOLIFT_STORE_CONF_DIR"},
+		{"a-b.c", "LINGOLIFT_A_B_C"},
+	}
+
+	for _, tt := range tests {
+		if got := EnvName(tt.key); got != tt.want {
+			t.Errorf("EnvName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLayer(t *testing.T) {
+	t.Setenv("LINGOLIFT_APP_CONF_METRICS_PATH", "/env")
+	t.Setenv("LINGOLIFT_APP_CONF_HTTP_CONF_ADDRESS", ":8081")
+	t.Setenv("app_id", "200")
+	t.Setenv("secret_id", "legacy")
+	t.Setenv("LINGOLIFT_TENCENT_SPEECH_CONF_SECRET_ID", "prefixed")
+	t.Setenv("LINGOLIFT_APP_CONF_REGION", "") // 空值不覆盖
+
+	c, err := layerConfig(t,
+		Override{Key: "app_conf.http_conf.address", Value: ":9090", Flag: "--web.listen-address"},
+		Override{Key: "upstream_conf.max_retries", Value: "5", Flag: "--set"},
+		Override{Key: "app_conf.enable_exporter_metrics", Value: "false", Flag: "--set"},
+		Override{Key: "tenants_conf.tenants", Value: "{school: {api_keys: [k1, k2]}}", Flag: "--set"},
+	)
+	if err != nil {
+		t.Fatalf("layer() = %v", err)
+	}
+
+	values := []struct {
+		key       string
+		got, want interface{}
+	}{
+		{"app_conf.http_conf.address", c.App.HTTP.Address, ":9090"},
+		{"app_conf.metrics_path", c.App.MetricsPath, "/env"},
+		{"app_conf.enable_exporter_metrics", c.App.EnableExporterMetrics, false},
+		{"app_conf.region", c.App.Region, ""},
+		{"tencent_speech_conf.app_id", c.Speech.AppID, "200"},
+		{"tencent_speech_conf.secret_id", c.Speech.SecretID, "prefixed"},
+		{"upstream_conf.max_retries", c.Upstream.MaxRetries, 5},
+		{"tenants_conf.tenants", c.Tenants.Tenants["school"].APIKeys, []string{"k1", "k2"}},
+	}
+	for _, v := range values {
+		if !reflect.DeepEqual(v.got, v.want) {
+			t.Errorf("%s = %v, want %v", v.key, v.got, v.want)
+		}
+	}
+
+	sources := map[string]Source{}
+	for _, s := range c.Sources() {
+		sources[s.Key] = s
+	}
+	want := []Source{
+		{"app_conf.http_conf.address", SourceFlag, "--web.listen-address"},
+		{"app_conf.metrics_path", SourceEnv, "LINGOLIFT_APP_CONF_METRICS_PATH"},
+		{"app_conf.region", SourceDefault, ""},
+		{"tencent_speech_conf.app_id", SourceEnv, "app_id"},
+		{"tencent_speech_conf.secret_id", SourceEnv, "LINGOLIFT_TENCENT_SPEECH_CONF_SECRET_ID"},
+		{"upstream_conf.max_retries", SourceFlag, "--set"},
+		{"upstream_conf.connect_timeout", SourceDefault, ""},
+		{"tenants_conf.tenants", SourceFlag, "--set"},
+	}
+	for _, w := range want {
+		if got := sources[w.Key]; got != w {
+			t.Errorf("source of %s = %v, want %v", w.Key, got, w)
+		}
+	}
+}
+
+func TestLayerFileSource(t *testing.T) {
+	c, err := layerConfig(t)
+	if err != nil {
+		t.Fatalf("layer() = %v", err)
+	}
+
+	sources := map[string]Source{}
+	for _, s := range c.Sources() {
+		sources[s.Key] = s
+	}
+	for _, key := range []string{"app_conf.http_conf.address", "app_conf.metrics_path", "upstream_conf.max_retries"} {
+		if want := (Source{Key: key, Source: SourceFile, Name: "cfg.yml"}); sources[key] != want {
+			t.Errorf("source of %s = %v, want %v", key, sources[key], want)
+		}
+	}
+	if c.Upstream.MaxRetries != 3 {
+		t.Errorf("upstream_conf.max_retries = %d, want 3", c.Upstream.MaxRetries)
+	}
+}
+
+func TestLayerInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		override Override
+	}{
+		{"unknown key", "", Override{Key: "app_conf.missing", Value: "1", Flag: "--set"}},
+		{"struct key", "", Override{Key: "app_conf.http_conf", Value: "{}", Flag: "--set"}},
+		{"invalid flag value", "", Override{Key: "upstream_conf.max_retries", Value: "many", Flag: "--set"}},
+		{"invalid env value", "LINGOLIFT_UPSTREAM_CONF_MAX_RETRIES", Override{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var overrides []Override
+			if len(tt.env) > 0 {
+				t.Setenv(tt.env, "many")
+			} else {
+				overrides = append(overrides, tt.override)
+			}
+			if _, err := layerConfig(t, overrides...); err == nil {
+				t.Error("layer() = nil, want error")
+			}
+		})
+	}
+}
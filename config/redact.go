@@ -0,0 +1,55 @@
+package config
+
+import (
+	"lingolift/pkg/log"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Redacted 返回密钥脱敏后的生效配置（YAML）
+func (c *LingoLiftConfig) Redacted() ([]byte, error) {
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.MapSlice
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(redactYAML(doc))
+}
+
+// String 密钥脱敏后的 YAML
+func (c *LingoLiftConfig) String() string {
+	content, err := c.Redacted()
+	if err != nil {
+		return err.Error()
+	}
+	return string(content)
+}
+
+// redactYAML 递归屏蔽键名为密钥的值
+func redactYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		for i, item := range v {
+			_, nested := item.Value.(yaml.MapSlice)
+			if key, ok := item.Key.(string); ok && log.IsSecret(key) && !nested {
+				if s, ok := item.Value.(string); ok {
+					v[i].Value = log.Mask(s)
+				} else if item.Value != nil {
+					v[i].Value = log.Masked
+				}
+				continue
+			}
+			v[i].Value = redactYAML(item.Value)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactYAML(v[i])
+		}
+	}
+	return v
+}
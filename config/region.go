@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+
+	"lingolift/errno"
+)
+
+// RegionsConfig 多区域部署时可路由到的区域
+// 本实例的存储位于 app_conf.region，其他区域的会话只评测不保存，保证学习者的录音和结果留在所在区域。
+type RegionsConfig struct {
+	Regions map[string]*Region `yaml:"regions"`
+}
+
+// Region 区域使用的语音服务账号和出口代理
+// 评测 SDK 的上游地址固定，区域的接入点通过 ProxyURL 指定的出口代理实现。
+type Region struct {
+	Name string `yaml:"-"`
+
+	// Speech 该区域的语音服务账号，为空时使用全局配置；不能与路由到该区域的租户账号同时配置
+	Speech *TencentCloudSpeechConfig `yaml:"speech"`
+
+	// ProxyURL 访问上游语音服务的区域出口代理（区域接入点），为空时直连
+	ProxyURL string `yaml:"proxy_url"`
+}
+
+// Enabled 是否配置了区域
+func (c *RegionsConfig) Enabled() bool {
+	return len(c.Regions) > 0
+}
+
+// check 检查区域配置，本实例所在区域必须在配置中
+func (c *RegionsConfig) check(home string) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if len(home) == 0 {
+		return fmt.Errorf("app_conf.region is required when regions_conf is configured")
+	}
+	if _, ok := c.Regions[home]; !ok {
+		return fmt.Errorf("app_conf.region %q is not defined in regions_conf", home)
+	}
+
+	for name, r := range c.Regions {
+		if r == nil {
+			c.Regions[name] = &Region{}
+			r = c.Regions[name]
+		}
+		r.Name = name
+
+		if r.Speech != nil {
+			if err := r.Speech.check("regions_conf.regions." + name + ".speech"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// clone 复制区域配置，用于刷新密钥时替换语音服务账号
+func (c *RegionsConfig) clone() *RegionsConfig {
+	next := &RegionsConfig{Regions: map[string]*Region{}}
+	for name, r := range c.Regions {
+		copied := *r
+		if r.Speech != nil {
+			speech := *r.Speech
+			copied.Speech = &speech
+		}
+		next.Regions[name] = &copied
+	}
+	return next
+}
+
+// ResolveRegion 确定会话所在区域：请求指定的区域，其次是租户所在区域，最后是本实例所在区域
+// 租户限定了区域时不能评测到其他区域。
+func (c *LingoLiftConfig) ResolveRegion(requested string, tenant *Tenant) (*Region, error) {
+	name := requested
+	if tenant != nil && len(tenant.Region) > 0 {
+		if len(name) > 0 && name != tenant.Region {
+			return nil, *errno.ErrUnsupportedRegion
+		}
+		name = tenant.Region
+	}
+	if len(name) == 0 {
+		name = c.App.Region
+	}
+
+	if !c.Regions.Enabled() {
+		// 未配置区域时只能使用本实例所在区域
+		if len(requested) > 0 && name != c.App.Region {
+			return nil, *errno.ErrNotFoundRegion
+		}
+		return &Region{Name: name}, nil
+	}
+
+	r, ok := c.Regions.Regions[name]
+	if !ok {
+		return nil, *errno.ErrNotFoundRegion
+	}
+	return r, nil
+}
+
+// Resident 会话数据是否可以保存在本实例
+func (c *LingoLiftConfig) Resident(r *Region) bool {
+	return r.Name == c.App.Region
+}
+
+// SpeechConfig 会话使用的语音服务账号：租户账号、区域账号、全局账号依次取第一个配置的
+// 加载配置时已拒绝租户账号和可路由区域的账号同时配置，因此租户账号和区域账号不会同时生效。
+func (c *LingoLiftConfig) SpeechConfig(tenant *Tenant, r *Region) TencentCloudSpeechConfig {
+	if tenant != nil && tenant.Speech != nil {
+		return *tenant.Speech
+	}
+	if r != nil && r.Speech != nil {
+		return *r.Speech
+	}
+	return c.Speech
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// current 当前生效的配置，重新加载时整体替换
+var current atomic.Pointer[LingoLiftConfig]
+
+// Current 返回当前生效的配置
+// 同一个请求内应只取一次，避免前后使用不同版本的配置。
+func Current() *LingoLiftConfig {
+	return current.Load()
+}
+
+// Reloader 配置文件变化或收到 SIGHUP 时重新加载配置
+// 新配置检查通过后才替换当前配置，已建立的评测会话继续使用原来的配置；
+// 检查失败时保留原配置并记录错误。
+type Reloader struct {
+	filename  string
+	overrides []Override
+	interval  time.Duration
+	hooks     []func(prev, next *LingoLiftConfig)
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader
+func NewReloader(filename string, overrides []Override, interval time.Duration) *Reloader {
+	r := &Reloader{filename: filename, overrides: overrides, interval: interval}
+	r.modTime, r.size = r.stat()
+	return r
+}
+
+// OnReload 注册替换配置后的回调
+func (r *Reloader) OnReload(fn func(prev, next *LingoLiftConfig)) {
+	r.hooks = append(r.hooks, fn)
+}
+
+// Run 监听 SIGHUP 并定时检查配置文件，interval 不大于 0 时只响应信号
+func (r *Reloader) Run() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-signals:
+			AppLogger.Info("Received SIGHUP, reloading configuration.", zap.String("file", r.filename))
+			r.Reload()
+		case <-tick:
+			if r.changed() {
+				AppLogger.Info("Configuration file changed, reloading.", zap.String("file", r.filename))
+				r.Reload()
+			}
+		}
+	}
+}
+
+// Reload 重新加载配置，成功后替换当前配置
+func (r *Reloader) Reload() error {
+	next := NewConfig()
+	if err := next.load(r.filename, r.overrides); err != nil {
+		AppLogger.Error("Failed to reload configuration, keep the running one.",
+			zap.String("file", r.filename), zap.Error(err))
+		return err
+	}
+
+	prev := current.Swap(next)
+	for _, key := range restartRequired(prev, next) {
+		AppLogger.Warn("Configuration changed but requires a restart to take effect.", zap.String("key", key))
+	}
+	for _, fn := range r.hooks {
+		fn(prev, next)
+	}
+
+	AppLogger.Info("Configuration reloaded.", zap.String("file", r.filename))
+	return nil
+}
+
+// changed 配置文件的修改时间或大小是否变化
+func (r *Reloader) changed() bool {
+	modTime, size := r.stat()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if modTime.Equal(r.modTime) && size == r.size {
+		return false
+	}
+	r.modTime, r.size = modTime, size
+	return true
+}
+
+func (r *Reloader) stat() (time.Time, int64) {
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
+
+// restartRequired 启动时使用、修改后需要重启才能生效的配置项
+func restartRequired(prev, next *LingoLiftConfig) []string {
+	var keys []string
+	for _, f := range []struct {
+		key        string
+		prev, next string
+	}{
+		{"app_conf.http_conf.address", prev.App.HTTP.Address, next.App.HTTP.Address},
+		{"app_conf.log_conf.filename", prev.App.Log.LogFileDir, next.App.Log.LogFileDir},
+		{"store_conf.dir", prev.Store.Dir, next.Store.Dir},
+		{"record_conf.signing_key", prev.Record.SigningKey, next.Record.SigningKey},
+	} {
+		if f.prev != f.next {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
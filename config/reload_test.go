@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// writeReloadConfig 写入可以通过检查的最小配置，appID 为空时检查失败
+func writeReloadConfig(t *testing.T, filename, address, metricsPath, appID string) {
+	t.Helper()
+	feedback, err := filepath.Abs(filepath.Join("..", "locales", "feedback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := fmt.Sprintf(`
+app_conf:
+  server_ip: 127.0.0.1
+  http_conf:
+    address: %q
+  metrics_path: %q
+tencent_speech_conf:
+  app_id: %q
+  secret_id: id
+  secret_key: key
+feedback_conf:
+  dir: %q
+`, address, metricsPath, appID, feedback)
+	if err = os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	defer func(l *zap.Logger) { AppLogger = l }(AppLogger)
+	AppLogger = zap.NewNop()
+	defer func(c *LingoLiftConfig) { current.Store(c) }(Current())
+
+	filename := filepath.Join(t.TempDir(), "cfg.yml")
+	writeReloadConfig(t, filename, ":8080", "/a", "100")
+	overrides := []Override{{Key: "upstream_conf.max_retries", Value: "4", Flag: "--set"}}
+
+	initial := NewConfig()
+	if err := initial.LoadFile(filename, overrides...); err != nil {
+		t.Fatalf("LoadFile() = %v", err)
+	}
+	if Current() != initial {
+		t.Fatal("LoadFile() did not set the current config")
+	}
+
+	r := NewReloader(filename, overrides, 0)
+	var reloads int
+	r.OnReload(func(prev, next *LingoLiftConfig) {
+		reloads++
+		if next != Current() {
+			t.Error("OnReload called before the config was replaced")
+		}
+	})
+
+	// 每步在上一步的基础上修改配置文件
+	steps := []struct {
+		name        string
+		address     string
+		metricsPath string
+		appID       string
+		wantErr     bool
+		want        string // 重新加载后生效的 metrics_path
+	}{
+		{"changed", ":8080", "/b", "100", false, "/b"},
+		{"invalid", ":8080", "/c", "", true, "/b"},
+		{"fixed", ":9090", "/d", "100", false, "/d"},
+	}
+
+	wantReloads := 0
+	for _, s := range steps {
+		prev := Current()
+		writeReloadConfig(t, filename, s.address, s.metricsPath, s.appID)
+		err := r.Reload()
+		if (err != nil) != s.wantErr {
+			t.Fatalf("%s: Reload() = %v, want error %v", s.name, err, s.wantErr)
+		}
+		if !s.wantErr {
+			wantReloads++
+		} else if Current() != prev {
+			t.Errorf("%s: Reload() replaced the config although it failed", s.name)
+		}
+
+		c := Current()
+		if c.App.MetricsPath != s.want {
+			t.Errorf("%s: metrics_path = %q, want %q", s.name, c.App.MetricsPath, s.want)
+		}
+		if c.Upstream.MaxRetries != 4 {
+			t.Errorf("%s: max_retries = %d, want the --set value 4", s.name, c.Upstream.MaxRetries)
+		}
+		if reloads != wantReloads {
+			t.Errorf("%s: OnReload called %d times, want %d", s.name, reloads, wantReloads)
+		}
+	}
+
+	// 已建立的会话持有的配置不受影响
+	if initial.App.MetricsPath != "/a" {
+		t.Errorf("initial metrics_path = %q, want it unchanged", initial.App.MetricsPath)
+	}
+}
+
+func TestReloaderChanged(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cfg.yml")
+	if err := os.WriteFile(filename, []byte("a: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := NewReloader(filename, nil, 0)
+
+	steps := []struct {
+		content string // 为空时不修改文件
+		want    bool
+	}{
+		{"", false},
+		{"a: 10\n", true},
+		{"", false},
+		{"a: 100\n", true},
+	}
+
+	for i, s := range steps {
+		if len(s.content) > 0 {
+			if err := os.WriteFile(filename, []byte(s.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := r.changed(); got != s.want {
+			t.Errorf("step %d: changed() = %v, want %v", i, got, s.want)
+		}
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	base := func() *LingoLiftConfig {
+		c := NewConfig()
+		c.fields() // 创建所有子配置
+		c.App.HTTP.Address = ":8080"
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *LingoLiftConfig)
+		want   []string
+	}{
+		{"unchanged", func(c *LingoLiftConfig) {}, nil},
+		{"reloadable", func(c *LingoLiftConfig) { c.App.MetricsPath = "/m" }, nil},
+		{"address", func(c *LingoLiftConfig) { c.App.HTTP.Address = ":9090" }, []string{"app_conf.http_conf.address"}},
+		{"store and key", func(c *LingoLiftConfig) {
+			c.Store.Dir = "data2"
+			c.Record.SigningKey = "new"
+		}, []string{"store_conf.dir", "record_conf.signing_key"}},
+	}
+
+	for _, tt := range tests {
+		next := base()
+		tt.modify(next)
+		if got := restartRequired(base(), next); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: restartRequired() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"lingolift/pkg/secret"
+
+	"go.uber.org/zap"
+)
+
+// resolveSecrets 解析语音服务配置中的密钥引用
+func (c *LingoLiftConfig) resolveSecrets() error {
+	if c.Secrets.RefreshInterval == 0 {
+		c.Secrets.RefreshInterval = secret.DefaultRefreshInterval
+	}
+
+	resolver, err := secret.NewResolver(c.Secrets)
+	if err != nil {
+		return err
+	}
+	c.resolver = resolver
+
+	for path, speech := range c.speechConfigs() {
+		if err = speech.resolve(resolver); err != nil {
+			return fmt.Errorf("%s.%w", path, err)
+		}
+	}
+	return nil
+}
+
+// secretFields 可以使用密钥引用的字段
+func (c *TencentCloudSpeechConfig) secretFields() map[string]*string {
+	return map[string]*string{
+		"app_id":     &c.AppID,
+		"secret_id":  &c.SecretID,
+		"secret_key": &c.SecretKey,
+		"token":      &c.Token,
+	}
+}
+
+// resolve 首次解析时记录引用，之后按记录的引用重新读取
+func (c *TencentCloudSpeechConfig) resolve(resolver *secret.Resolver) error {
+	if c.refs == nil {
+		c.refs = map[string]string{}
+		for key, value := range c.secretFields() {
+			if secret.IsRef(*value) {
+				c.refs[key] = *value
+			}
+		}
+	}
+
+	fields := c.secretFields()
+	for key, ref := range c.refs {
+		value, err := resolver.Resolve(ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		*fields[key] = value
+	}
+	return nil
+}
+
+// RefreshSecrets 定期重新读取密钥引用，值变化时替换当前配置，只影响之后创建的会话
+func RefreshSecrets() {
+	for {
+		interval := Current().Secrets.RefreshInterval
+		if interval <= 0 {
+			// 不刷新时仍定期检查，重新加载配置后可能启用
+			interval = secret.DefaultRefreshInterval
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+
+		if Current().Secrets.RefreshInterval > 0 {
+			refreshSecrets()
+		}
+	}
+}
+
+func refreshSecrets() {
+	prev := Current()
+
+	next := *prev
+	next.Tenants = prev.Tenants.clone()
+	next.Regions = prev.Regions.clone()
+	next.Fallback = prev.Fallback.clone()
+
+	changed := false
+	for path, speech := range next.speechConfigs() {
+		speechChanged, err := speech.refresh(prev.resolver)
+		if err != nil {
+			AppLogger.Error("Failed to refresh secrets, keep the current ones.", zap.String("key", path), zap.Error(err))
+			return
+		}
+		changed = changed || speechChanged
+	}
+	if !changed {
+		return
+	}
+
+	// 期间配置被重新加载时放弃本次刷新，新配置已经读取了最新的密钥
+	if current.CompareAndSwap(prev, &next) {
+		AppLogger.Info("Speech credentials refreshed.")
+	}
+}
+
+// refresh 重新解析密钥引用，返回值是否变化
+func (c *TencentCloudSpeechConfig) refresh(resolver *secret.Resolver) (bool, error) {
+	if len(c.refs) == 0 {
+		return false, nil
+	}
+
+	prev := *c
+	if err := c.resolve(resolver); err != nil {
+		return false, err
+	}
+	return c.AppID != prev.AppID || c.SecretID != prev.SecretID ||
+		c.SecretKey != prev.SecretKey || c.Token != prev.Token, nil
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"lingolift/errno"
+	"lingolift/pkg/speech"
+)
+
+const (
+	// DefaultTenantHeader 请求头中的 API Key
+	DefaultTenantHeader = "X-API-KEY"
+
+	// DefaultTenantQuery 浏览器建立 WebSocket 连接时无法设置请求头，使用查询参数
+	DefaultTenantQuery = "api_key"
+)
+
+// TenantsConfig 租户（学校）配置，未配置租户时所有请求使用全局的语音服务账号
+type TenantsConfig struct {
+	Header  string             `yaml:"header"`
+	Query   string             `yaml:"query"`
+	Tenants map[string]*Tenant `yaml:"tenants"`
+
+	keys map[string]*Tenant
+}
+
+// Tenant 租户使用独立的语音服务账号和默认参数
+type Tenant struct {
+	Name    string   `yaml:"-"`
+	APIKeys []string `yaml:"api_keys"`
+
+	// Speech 语音服务账号，为空时使用全局配置
+	Speech *TencentCloudSpeechConfig `yaml:"speech"`
+
+	// DefaultEngineType 客户端未指定且无法自动检测时使用的引擎类型
+	DefaultEngineType string `yaml:"default_engine_type"`
+
+	// ScoringProfile 租户使用的评分配置，为空时使用服务端默认配置
+	ScoringProfile string `yaml:"scoring_profile"`
+
+	// ScoringProfiles 允许客户端选择的评分配置，为空时客户端不能选择
+	ScoringProfiles []string `yaml:"scoring_profiles"`
+
+	// Region 学习者数据所在区域，为空时不限制
+	Region string `yaml:"region"`
+
+	Limits TenantLimits `yaml:"limits"`
+
+	// AllowedOrigins 允许访问的页面来源，为空时不限制；配置后使用查询参数 API Key 的请求必须携带 Origin
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// TenantLimits 租户限制，0 表示不限制
+type TenantLimits struct {
+	// MaxSessions 同时进行的评测会话数
+	MaxSessions int `yaml:"max_sessions"`
+}
+
+// activeSessions 各租户进行中的会话数，按名称计数，重新加载配置后保留
+var activeSessions sync.Map
+
+// Enabled 是否配置了租户
+func (c *TenantsConfig) Enabled() bool {
+	return len(c.Tenants) > 0
+}
+
+// check 检查租户配置，建立 API Key 索引
+func (c *TenantsConfig) check(scoring *speech.ScoringConfig, regions *RegionsConfig) error {
+	if len(c.Header) == 0 {
+		c.Header = DefaultTenantHeader
+	}
+	if len(c.Query) == 0 {
+		c.Query = DefaultTenantQuery
+	}
+
+	c.keys = map[string]*Tenant{}
+	for name, t := range c.Tenants {
+		if t == nil {
+			return fmt.Errorf("tenant %q is empty", name)
+		}
+		t.Name = name
+
+		if len(t.APIKeys) == 0 {
+			return fmt.Errorf("tenant %q: api_keys is required", name)
+		}
+		for _, key := range t.APIKeys {
+			if other, ok := c.keys[key]; ok {
+				return fmt.Errorf("tenant %q: api key is already used by tenant %q", name, other.Name)
+			}
+			c.keys[key] = t
+		}
+
+		if len(t.ScoringProfile) > 0 {
+			if _, err := scoring.Lookup(t.ScoringProfile); err != nil {
+				return fmt.Errorf("tenant %q: scoring_profile %q is not defined", name, t.ScoringProfile)
+			}
+		}
+		for _, profile := range t.ScoringProfiles {
+			if _, err := scoring.Lookup(profile); err != nil {
+				return fmt.Errorf("tenant %q: scoring_profiles %q is not defined", name, profile)
+			}
+		}
+		if len(t.Region) > 0 && regions.Enabled() {
+			if _, ok := regions.Regions[t.Region]; !ok {
+				return fmt.Errorf("tenant %q: region %q is not defined in regions_conf", name, t.Region)
+			}
+		}
+		if t.Limits.MaxSessions < 0 {
+			return fmt.Errorf("tenant %q: max_sessions must not be negative", name)
+		}
+
+		if t.Speech != nil {
+			if err := t.Speech.check("tenants_conf.tenants." + name + ".speech"); err != nil {
+				return err
+			}
+			if err := t.checkRegionSpeech(regions); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// clone 复制租户配置，用于刷新密钥时替换语音服务账号
+func (c *TenantsConfig) clone() *TenantsConfig {
+	next := *c
+	next.Tenants = map[string]*Tenant{}
+	next.keys = map[string]*Tenant{}
+	for name, t := range c.Tenants {
+		copied := *t
+		if t.Speech != nil {
+			speech := *t.Speech
+			copied.Speech = &speech
+		}
+		next.Tenants[name] = &copied
+		for _, key := range copied.APIKeys {
+			next.keys[key] = &copied
+		}
+	}
+	return &next
+}
+
+// Resolve 按请求头或查询参数中的 API Key 查找租户，未配置租户时返回 nil
+func (c *TenantsConfig) Resolve(r *http.Request) (*Tenant, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+
+	key, inQuery := r.Header.Get(c.Header), false
+	if len(key) == 0 {
+		key, inQuery = r.URL.Query().Get(c.Query), true
+	}
+	if len(key) == 0 {
+		return nil, errno.ErrMissingHeader.WithFmt(c.Header)
+	}
+
+	t, ok := c.keys[key]
+	if !ok {
+		return nil, *errno.ErrUnauthorized
+	}
+
+	// 查询参数中的 API Key 供浏览器使用，会暴露在页面中，配置了允许的来源时必须携带 Origin；
+	// 请求头中的 API Key 来自服务端或原生客户端，可以不带 Origin
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 && inQuery && len(t.AllowedOrigins) > 0 {
+		return nil, *errno.ErrPermissionDenied
+	}
+	if len(origin) > 0 && !t.AllowOrigin(origin) {
+		return nil, *errno.ErrPermissionDenied
+	}
+
+	return t, nil
+}
+
+// checkRegionSpeech 使用独立账号的租户不能路由到有独立账号的区域，避免两个账号都配置时不确定使用哪一个
+func (t *Tenant) checkRegionSpeech(regions *RegionsConfig) error {
+	for name, r := range regions.Regions {
+		if len(t.Region) > 0 && name != t.Region {
+			continue
+		}
+		if r != nil && r.Speech != nil {
+			return fmt.Errorf("tenant %q: speech and regions_conf.regions.%s.speech are both set, "+
+				"configure the account on only one of them or pin the tenant to another region", t.Name, name)
+		}
+	}
+	return nil
+}
+
+// AllowOrigin 页面来源是否允许
+func (t *Tenant) AllowOrigin(origin string) bool {
+	if len(t.AllowedOrigins) == 0 {
+		return true
+	}
+	for _, allowed := range t.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowScoringProfile 客户端是否可以选择该评分配置
+func (t *Tenant) AllowScoringProfile(profile string) bool {
+	if t == nil {
+		return false
+	}
+	if profile == t.ScoringProfile {
+		return true
+	}
+	for _, allowed := range t.ScoringProfiles {
+		if allowed == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// Acquire 占用一个会话名额，会话结束后调用 release 归还
+func (t *Tenant) Acquire() (release func(), err error) {
+	if t == nil {
+		return func() {}, nil
+	}
+
+	value, _ := activeSessions.LoadOrStore(t.Name, new(atomic.Int64))
+	active := value.(*atomic.Int64)
+	if n := active.Add(1); t.Limits.MaxSessions > 0 && n > int64(t.Limits.MaxSessions) {
+		active.Add(-1)
+		return nil, errno.ErrExceedsLimit.WithFmt(fmt.Sprintf("tenant %s has reached the limit of %d concurrent sessions.", t.Name, t.Limits.MaxSessions))
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { active.Add(-1) })
+	}, nil
+}
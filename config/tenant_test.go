@@ -0,0 +1,132 @@
+package config
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"lingolift/errno"
+	"lingolift/pkg/speech"
+)
+
+func TestTenantCheckRegionSpeech(t *testing.T) {
+	account := &TencentCloudSpeechConfig{AppID: "1", SecretID: "id", SecretKey: "key"}
+	regions := &RegionsConfig{Regions: map[string]*Region{
+		"ap-shanghai":  {Name: "ap-shanghai"},
+		"ap-singapore": {Name: "ap-singapore", Speech: account},
+	}}
+
+	tests := []struct {
+		name    string
+		tenant  Tenant
+		regions *RegionsConfig
+		wantErr bool
+	}{
+		{"pinned to a region without account", Tenant{Name: "a", Speech: account, Region: "ap-shanghai"}, regions, false},
+		{"pinned to a region with account", Tenant{Name: "a", Speech: account, Region: "ap-singapore"}, regions, true},
+		{"can route to a region with account", Tenant{Name: "a", Speech: account}, regions, true},
+		{"no regions configured", Tenant{Name: "a", Speech: account}, &RegionsConfig{}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.tenant.checkRegionSpeech(tt.regions)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkRegionSpeech() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSpeechConfig(t *testing.T) {
+	c := NewConfig()
+	c.Speech = TencentCloudSpeechConfig{AppID: "global"}
+	tenant := &Tenant{Speech: &TencentCloudSpeechConfig{AppID: "tenant"}}
+	region := &Region{Speech: &TencentCloudSpeechConfig{AppID: "region"}}
+
+	tests := []struct {
+		name   string
+		tenant *Tenant
+		region *Region
+		want   string
+	}{
+		{"tenant account", tenant, &Region{}, "tenant"},
+		{"region account", &Tenant{}, region, "region"},
+		{"no tenant", nil, region, "region"},
+		{"global account", nil, &Region{}, "global"},
+	}
+
+	for _, tt := range tests {
+		if got := c.SpeechConfig(tt.tenant, tt.region).AppID; got != tt.want {
+			t.Errorf("%s: SpeechConfig().AppID = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTenantsResolve(t *testing.T) {
+	c := &TenantsConfig{Tenants: map[string]*Tenant{
+		"web":    {APIKeys: []string{"web-key"}, AllowedOrigins: []string{"https://school.example/"}},
+		"native": {APIKeys: []string{"native-key"}},
+	}}
+	if err := c.check(&speech.ScoringConfig{}, &RegionsConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		origin string
+		tenant string
+		code   string
+	}{
+		{"header key without origin", "/ws", "web-key", "", "web", ""},
+		{"query key with allowed origin", "/ws?api_key=web-key", "", "https://school.example", "web", ""},
+		{"query key without origin", "/ws?api_key=web-key", "", "", "", errno.ErrPermissionDenied.Code},
+		{"query key with other origin", "/ws?api_key=web-key", "", "https://evil.example", "", errno.ErrPermissionDenied.Code},
+		{"header key with other origin", "/ws", "web-key", "https://evil.example", "", errno.ErrPermissionDenied.Code},
+		{"no allowed origins", "/ws?api_key=native-key", "", "", "native", ""},
+		{"unknown key", "/ws", "other", "", "", errno.ErrUnauthorized.Code},
+		{"missing key", "/ws", "", "", "", errno.ErrMissingHeader.Code},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if len(tt.header) > 0 {
+			r.Header.Set(DefaultTenantHeader, tt.header)
+		}
+		if len(tt.origin) > 0 {
+			r.Header.Set("Origin", tt.origin)
+		}
+
+		tenant, err := c.Resolve(r)
+		if len(tt.code) > 0 {
+			if e, ok := err.(errno.Err); !ok || e.Code != tt.code {
+				t.Errorf("%s: Resolve() error = %v, want %s", tt.name, err, tt.code)
+			}
+			continue
+		}
+		if err != nil || tenant == nil || tenant.Name != tt.tenant {
+			t.Errorf("%s: Resolve() = %v, %v, want tenant %s", tt.name, tenant, err, tt.tenant)
+		}
+	}
+}
+
+func TestAllowScoringProfile(t *testing.T) {
+	tenant := &Tenant{ScoringProfile: "kids", ScoringProfiles: []string{"exam"}}
+
+	tests := []struct {
+		tenant  *Tenant
+		profile string
+		want    bool
+	}{
+		{tenant, "kids", true},
+		{tenant, "exam", true},
+		{tenant, "strict", false},
+		{&Tenant{}, "exam", false},
+		{nil, "exam", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tenant.AllowScoringProfile(tt.profile); got != tt.want {
+			t.Errorf("AllowScoringProfile(%q) = %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+}
@@ -14,6 +14,7 @@ require (
 	github.com/toolkits/net v0.0.0-20160910085801-3f39ab6fe3ce
 	github.com/tylerb/graceful v1.2.15
 	go.uber.org/zap v1.27.0
+	golang.org/x/text v0.23.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
@@ -41,7 +42,6 @@ require (
 	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/net v0.38.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/time v0.8.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 )
@@ -0,0 +1,170 @@
+package anticheat
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/audio"
+	"lingolift/pkg/store"
+)
+
+const (
+	// 与其他用户录音的指纹相似度达到该值视为回放（不相关录音约为 0.5）
+	ReplayThreshold = 0.65
+
+	// 每次最多比较的历史录音数量，取索引中命中最多的录音
+	maxCandidates = 20
+)
+
+// 可疑原因，回放特征见 audio.Playback*
+const ReasonReplay = "replay_match"
+
+var (
+	prints *store.Table[Print]
+
+	// indexes 按租户和文本分组的指纹索引，只与同一租户朗读同一文本的录音比较
+	mu      sync.RWMutex
+	indexes map[string]*index
+
+	// checking 限制同时计算指纹的数量
+	checking = make(chan struct{}, runtime.NumCPU())
+)
+
+// index 一组录音的指纹索引，索引中的编号为 prints 的下标
+type index struct {
+	fingerprints *audio.FingerprintIndex
+	prints       []*Print
+}
+
+// Print 一次评测录音的指纹
+type Print struct {
+	SessionID   string    `json:"session_id"`
+	Tenant      string    `json:"tenant,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+	TextKey     string    `json:"text_key"`
+	Fingerprint []uint32  `json:"fingerprint"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Report 防作弊检测结果
+type Report struct {
+	Suspicious     bool           `json:"suspicious"`
+	Reasons        []string       `json:"reasons,omitempty"`
+	MatchedSession string         `json:"matched_session,omitempty"`
+	MatchedUser    string         `json:"matched_user,omitempty"`
+	Similarity     float64        `json:"similarity"`
+	Playback       audio.Playback `json:"playback"`
+}
+
+// Init 打开录音指纹表并建立索引
+func Init(db *store.DB) (err error) {
+	if prints, err = store.NewTable[Print](db, "fingerprints"); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	indexes = map[string]*index{}
+	for _, p := range prints.List(nil) {
+		add(p)
+	}
+	return nil
+}
+
+// Check 计算录音指纹并保存，与同一租户其他用户朗读同一文本的录音比较，同时检查回放特征
+// 计算量较大，应在最终结果发送给客户端后调用。
+func Check(sessionID, tenant, userID, refText string, samples []int16, sampleRate int) (*Report, error) {
+	checking <- struct{}{}
+	defer func() { <-checking }()
+
+	report := &Report{Playback: audio.AnalyzePlayback(samples, sampleRate)}
+	if report.Playback.Suspicious() {
+		report.Reasons = append(report.Reasons, report.Playback.Reasons...)
+	}
+
+	p := &Print{
+		SessionID:   sessionID,
+		Tenant:      tenant,
+		UserID:      userID,
+		TextKey:     textKey(refText),
+		Fingerprint: audio.Fingerprint(samples, sampleRate),
+		CreatedAt:   time.Now(),
+	}
+	if len(p.Fingerprint) == 0 {
+		report.Suspicious = len(report.Reasons) > 0
+		return report, nil
+	}
+
+	for _, c := range candidates(p) {
+		similarity := audio.Similarity(p.Fingerprint, c.Fingerprint)
+		if similarity > report.Similarity {
+			report.Similarity = similarity
+			report.MatchedSession = c.SessionID
+			report.MatchedUser = c.UserID
+		}
+	}
+	if report.Similarity >= ReplayThreshold {
+		report.Reasons = append(report.Reasons, ReasonReplay)
+	} else {
+		report.MatchedSession, report.MatchedUser = "", ""
+	}
+	report.Suspicious = len(report.Reasons) > 0
+
+	if err := prints.Put(p.SessionID, p); err != nil {
+		return report, errno.ErrDatabase.WithRawErr(err)
+	}
+
+	mu.Lock()
+	add(p)
+	mu.Unlock()
+	return report, nil
+}
+
+// candidates 同一租户朗读同一文本的其他用户的录音中，指纹索引命中最多的录音
+// 匿名用户与其他所有录音比较。
+func candidates(p *Print) []*Print {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	x := indexes[indexKey(p.Tenant, p.TextKey)]
+	if x == nil {
+		return nil
+	}
+
+	var list []*Print
+	for _, id := range x.fingerprints.Match(p.Fingerprint) {
+		c := x.prints[id]
+		if c.SessionID == p.SessionID || (len(p.UserID) > 0 && c.UserID == p.UserID) {
+			continue
+		}
+		list = append(list, c)
+		if len(list) == maxCandidates {
+			break
+		}
+	}
+	return list
+}
+
+// add 把指纹加入索引，调用者需持有写锁
+func add(p *Print) {
+	key := indexKey(p.Tenant, p.TextKey)
+	x := indexes[key]
+	if x == nil {
+		x = &index{fingerprints: audio.NewFingerprintIndex()}
+		indexes[key] = x
+	}
+	x.fingerprints.Add(len(x.prints), p.Fingerprint)
+	x.prints = append(x.prints, p)
+}
+
+func indexKey(tenant, textKey string) string {
+	return tenant + "\n" + textKey
+}
+
+// textKey 忽略大小写和空白差异
+func textKey(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
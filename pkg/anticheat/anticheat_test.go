@@ -0,0 +1,84 @@
+package anticheat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"lingolift/pkg/store"
+)
+
+const sampleRate = 16000
+
+func TestMain(m *testing.M) {
+	db, err := store.Open(nil)
+	if err != nil {
+		panic(err)
+	}
+	if err = Init(db); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+// recording 每 50ms 随机换一组音调，模拟有声的录音
+func recording(seed int64) []int16 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]int16, 3*sampleRate)
+
+	var freqs [3]float64
+	for i := range samples {
+		if i%(sampleRate/20) == 0 {
+			for k := range freqs {
+				freqs[k] = 200 + r.Float64()*3000
+			}
+		}
+		var v float64
+		for _, f := range freqs {
+			v += math.Sin(2 * math.Pi * f * float64(i) / sampleRate)
+		}
+		samples[i] = int16(v * 6000)
+	}
+	return samples
+}
+
+func TestCheck(t *testing.T) {
+	if _, err := Check("s0", "school_a", "alice", "Hello World", recording(1), sampleRate); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		session string
+		tenant  string
+		user    string
+		text    string
+		seed    int64
+		replay  bool
+	}{
+		{"same user", "s1", "school_a", "alice", "Hello World", 1, false},
+		{"other tenant", "s2", "school_b", "bob", "Hello World", 1, false},
+		{"other text", "s3", "school_a", "bob", "Good morning", 1, false},
+		{"different recording", "s4", "school_a", "carol", "Hello World", 2, false},
+		{"other user replays", "s5", "school_a", "bob", "hello  world", 1, true},
+		{"anonymous replays", "s6", "school_a", "", "Hello World", 1, true},
+	}
+
+	for _, tt := range tests {
+		report, err := Check(tt.session, tt.tenant, tt.user, tt.text, recording(tt.seed), sampleRate)
+		if err != nil {
+			t.Fatalf("%s: Check() = %v", tt.name, err)
+		}
+
+		var replay bool
+		for _, reason := range report.Reasons {
+			replay = replay || reason == ReasonReplay
+		}
+		if replay != tt.replay {
+			t.Errorf("%s: replay = %v (similarity %.2f), want %v", tt.name, replay, report.Similarity, tt.replay)
+		}
+		if replay && (report.MatchedUser == tt.user || report.MatchedSession == tt.session) {
+			t.Errorf("%s: matched own recording %s of %q", tt.name, report.MatchedSession, report.MatchedUser)
+		}
+	}
+}
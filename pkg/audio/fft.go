@@ -0,0 +1,73 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// fft 原地计算快速傅里叶变换，len(x) 必须是 2 的幂
+func fft(x []complex128) {
+	n := len(x)
+
+	// 位反转重排
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	twiddles := make([]complex128, n/2)
+	for k := range twiddles {
+		twiddles[k] = cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(n)))
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half, step := size/2, n/size
+		for start := 0; start < n; start += size {
+			for k := 0; k < half; k++ {
+				t := twiddles[k*step] * x[start+k+half]
+				u := x[start+k]
+				x[start+k] = u + t
+				x[start+k+half] = u - t
+			}
+		}
+	}
+}
+
+// powerSpectrum 加汉宁窗后计算功率谱，返回 0 到奈奎斯特频率的 n/2+1 个频点
+func powerSpectrum(frame []int16, window []float64, buf []complex128) []float64 {
+	for i := range buf {
+		buf[i] = complex(float64(frame[i])/math.MaxInt16*window[i], 0)
+	}
+	fft(buf)
+
+	power := make([]float64, len(buf)/2+1)
+	for i := range power {
+		re, im := real(buf[i]), imag(buf[i])
+		power[i] = re*re + im*im
+	}
+	return power
+}
+
+// hann 汉宁窗
+func hann(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+	}
+	return w
+}
+
+// nextPow2 不小于 n 的最小 2 的幂
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
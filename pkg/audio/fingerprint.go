@@ -0,0 +1,193 @@
+package audio
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// 指纹的帧长（毫秒），帧移为帧长的 1/4
+	fingerprintFrameMs = 64
+
+	// 指纹使用的频率范围（Hz），覆盖语音的主要能量
+	fingerprintMinHz = 200
+	fingerprintMaxHz = 4000
+
+	// 相邻频带能量差分得到 32 位子指纹，需要 33 个频带
+	fingerprintBands = 33
+
+	// 低于该电平（dBFS）的帧视为静音，子指纹记为 0，比较时跳过
+	fingerprintSilence = -50.0
+
+	// 比较时最多错开的帧数（约 2 秒），以及最少需要重叠的有声帧数
+	maxFingerprintShift  = 128
+	minFingerprintFrames = 32
+)
+
+// Fingerprint 音频指纹：每帧一个 32 位子指纹（Haitsma-Kalker 算法），
+// 每一位表示相邻两个频带的能量差相对上一帧是否增大。
+// 对音量、轻微噪声和扬声器播放后再录音有一定鲁棒性，用于发现重复提交的录音。
+func Fingerprint(samples []int16, sampleRate int) []uint32 {
+	if sampleRate <= 0 {
+		return nil
+	}
+
+	size := nextPow2(sampleRate * fingerprintFrameMs / 1000)
+	hop := size / 4
+	if len(samples) < size {
+		return nil
+	}
+
+	edges := bandEdges(size, sampleRate)
+	window := hann(size)
+	buf := make([]complex128, size)
+
+	var (
+		prints []uint32
+		prev   []float64
+	)
+	for start := 0; start+size <= len(samples); start += hop {
+		frame := samples[start : start+size]
+		energy := bandEnergies(powerSpectrum(frame, window, buf), edges)
+
+		var sub uint32
+		if prev != nil && Level(frame) >= fingerprintSilence {
+			for m := 0; m < fingerprintBands-1; m++ {
+				if energy[m]-energy[m+1]-(prev[m]-prev[m+1]) > 0 {
+					sub |= 1 << m
+				}
+			}
+		}
+		prints = append(prints, sub)
+		prev = energy
+	}
+
+	return prints
+}
+
+// Similarity 两段指纹的相似度（0-1），在允许的错位范围内取最高值
+// 不相关的音频约为 0.5，同一录音的回放通常在 0.75 以上；有声帧重叠不足时返回 0。
+func Similarity(a, b []uint32) float64 {
+	var best float64
+	for shift := -maxFingerprintShift; shift <= maxFingerprintShift; shift++ {
+		var frames, diff int
+		for i := range a {
+			j := i + shift
+			if j < 0 || j >= len(b) {
+				continue
+			}
+			if a[i] == 0 || b[j] == 0 {
+				continue
+			}
+			frames++
+			diff += bits.OnesCount32(a[i] ^ b[j])
+		}
+		if frames < minFingerprintFrames {
+			continue
+		}
+		best = math.Max(best, 1-float64(diff)/float64(frames*32))
+	}
+	return best
+}
+
+// FingerprintIndex 指纹的倒排索引，按子指纹的每个字节分桶
+// 查询时按错位统计命中次数：同一录音的回放在正确的错位上命中集中，不相关录音的命中分散，
+// 只需对命中最多的少数录音计算 Similarity。
+type FingerprintIndex struct {
+	buckets map[uint16][]posting
+}
+
+// posting 编号为 id 的指纹第 frame 帧
+type posting struct {
+	id, frame int
+}
+
+// NewFingerprintIndex 创建空索引
+func NewFingerprintIndex() *FingerprintIndex {
+	return &FingerprintIndex{buckets: map[uint16][]posting{}}
+}
+
+// Add 把编号为 id 的指纹加入索引，静音帧不加入
+func (x *FingerprintIndex) Add(id int, fp []uint32) {
+	for i, sub := range fp {
+		if sub == 0 {
+			continue
+		}
+		for b := 0; b < 4; b++ {
+			key := bucketKey(sub, b)
+			x.buckets[key] = append(x.buckets[key], posting{id: id, frame: i})
+		}
+	}
+}
+
+// Match 返回与指纹有命中的编号，按最佳错位上的命中次数从多到少排序
+func (x *FingerprintIndex) Match(fp []uint32) []int {
+	type vote struct {
+		id, shift int
+	}
+	votes := map[vote]int{}
+	for i, sub := range fp {
+		if sub == 0 {
+			continue
+		}
+		for b := 0; b < 4; b++ {
+			for _, p := range x.buckets[bucketKey(sub, b)] {
+				shift := p.frame - i
+				if shift < -maxFingerprintShift || shift > maxFingerprintShift {
+					continue
+				}
+				votes[vote{p.id, shift}]++
+			}
+		}
+	}
+
+	best := map[int]int{}
+	for v, n := range votes {
+		if n > best[v.id] {
+			best[v.id] = n
+		}
+	}
+	ids := make([]int, 0, len(best))
+	for id := range best {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if best[ids[i]] != best[ids[j]] {
+			return best[ids[i]] > best[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// bucketKey 子指纹第 b 个字节所在的桶
+func bucketKey(sub uint32, b int) uint16 {
+	return uint16(b)<<8 | uint16(sub>>(8*b)&0xff)
+}
+
+// bandEdges 对数划分频带，返回每个频带的起止频点
+func bandEdges(size, sampleRate int) []int {
+	maxHz := math.Min(fingerprintMaxHz, float64(sampleRate)/2)
+	ratio := math.Pow(maxHz/fingerprintMinHz, 1/float64(fingerprintBands))
+
+	edges := make([]int, fingerprintBands+1)
+	for i := range edges {
+		hz := fingerprintMinHz * math.Pow(ratio, float64(i))
+		edges[i] = int(math.Round(hz * float64(size) / float64(sampleRate)))
+		if i > 0 && edges[i] <= edges[i-1] {
+			edges[i] = edges[i-1] + 1
+		}
+	}
+	return edges
+}
+
+func bandEnergies(power []float64, edges []int) []float64 {
+	energy := make([]float64, len(edges)-1)
+	for m := range energy {
+		for k := edges[m]; k < edges[m+1] && k < len(power); k++ {
+			energy[m] += power[k]
+		}
+	}
+	return energy
+}
@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const testSampleRate = 16000
+
+// tones 每 50ms 随机换一组音调，模拟有声的录音
+func tones(seed int64, seconds float64) []int16 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]int16, int(seconds*testSampleRate))
+	step := testSampleRate / 20
+
+	var freqs [3]float64
+	for i := range samples {
+		if i%step == 0 {
+			for k := range freqs {
+				freqs[k] = 200 + r.Float64()*3000
+			}
+		}
+		t := float64(i) / testSampleRate
+		var v float64
+		for _, f := range freqs {
+			v += math.Sin(2 * math.Pi * f * t)
+		}
+		samples[i] = int16(v * 6000)
+	}
+	return samples
+}
+
+// replay 模拟扬声器回放后再录音：延迟、音量变化和噪声
+func replay(samples []int16, delay int, gain float64) []int16 {
+	r := rand.New(rand.NewSource(1))
+	out := make([]int16, delay+len(samples))
+	for i := range out {
+		v := r.NormFloat64() * 300
+		if i >= delay {
+			v += float64(samples[i-delay]) * gain
+		}
+		out[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))
+	}
+	return out
+}
+
+func TestFingerprintIndex(t *testing.T) {
+	original := Fingerprint(tones(1, 3), testSampleRate)
+
+	x := NewFingerprintIndex()
+	for id := 0; id < 10; id++ {
+		fp := original
+		if id != 7 {
+			fp = Fingerprint(tones(int64(100+id), 3), testSampleRate)
+		}
+		x.Add(id, fp)
+	}
+
+	tests := []struct {
+		name     string
+		samples  []int16
+		match    bool
+		minScore float64
+	}{
+		{"same recording", tones(1, 3), true, 0.99},
+		{"delayed replay", replay(tones(1, 3), 4000, 0.5), true, 0.65},
+		{"unrelated recording", tones(2, 3), false, 0},
+	}
+
+	for _, tt := range tests {
+		fp := Fingerprint(tt.samples, testSampleRate)
+		ids := x.Match(fp)
+		if tt.match {
+			if len(ids) == 0 || ids[0] != 7 {
+				t.Errorf("%s: Match() = %v, want 7 first", tt.name, ids)
+				continue
+			}
+			if got := Similarity(fp, original); got < tt.minScore {
+				t.Errorf("%s: Similarity() = %.2f, want >= %.2f", tt.name, got, tt.minScore)
+			}
+			continue
+		}
+		if got := Similarity(fp, original); got >= 0.65 {
+			t.Errorf("%s: Similarity() = %.2f, want < 0.65", tt.name, got)
+		}
+	}
+}
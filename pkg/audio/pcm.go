@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// DefaultSampleRate 评测引擎要求的采样率
+	DefaultSampleRate = 16000
+
+	// DefaultBitDepth 评测引擎要求的位深
+	DefaultBitDepth = 16
+
+	// DefaultChannels 评测引擎要求的声道数
+	DefaultChannels = 1
+)
+
+// Format describes the layout of a PCM stream.
+type Format struct {
+	SampleRate int `json:"sample_rate"`
+	BitDepth   int `json:"bit_depth"`
+	Channels   int `json:"channels"`
+}
+
+// DefaultFormat is the format the front-end records and the engine expects:
+// 16kHz, 16bit, mono.
+var DefaultFormat = Format{
+	SampleRate: DefaultSampleRate,
+	BitDepth:   DefaultBitDepth,
+	Channels:   DefaultChannels,
+}
+
+// BytesPerSecond
+func (f Format) BytesPerSecond() int {
+	return f.SampleRate * f.Channels * f.BitDepth / 8
+}
+
+// Duration returns the duration in seconds of n bytes of audio in this format.
+func (f Format) Duration(n int) float64 {
+	bps := f.BytesPerSecond()
+	if bps <= 0 {
+		return 0
+	}
+	return float64(n) / float64(bps)
+}
+
+// Decode 解码客户端上传的音频块
+// 支持带 RIFF/WAVE 头的 wav 数据和裸 16bit 小端 PCM，裸 PCM 按 f 描述的格式解析。
+// 返回单声道采样（多声道取平均）以及实际使用的格式。
+func Decode(data []byte, f Format) ([]int16, Format, error) {
+	if bytes.HasPrefix(data, []byte("RIFF")) {
+		return decodeWAV(data)
+	}
+
+	if f.BitDepth != 16 {
+		return nil, f, fmt.Errorf("unsupported bit depth: %d", f.BitDepth)
+	}
+	if f.Channels <= 0 {
+		f.Channels = DefaultChannels
+	}
+
+	return decodePCM16(data, f.Channels), f, nil
+}
+
+// decodePCM16 将 16bit 小端 PCM 转换为单声道采样
+func decodePCM16(data []byte, channels int) []int16 {
+	frameSize := 2 * channels
+	samples := make([]int16, 0, len(data)/frameSize)
+
+	for i := 0; i+frameSize <= len(data); i += frameSize {
+		var sum int
+		for c := 0; c < channels; c++ {
+			sum += int(int16(binary.LittleEndian.Uint16(data[i+2*c:])))
+		}
+		samples = append(samples, int16(sum/channels))
+	}
+
+	return samples
+}
+
+// decodeWAV 解析 wav 头并返回 data 块中的采样
+func decodeWAV(data []byte) ([]int16, Format, error) {
+	var f Format
+
+	if len(data) < 12 || string(data[8:12]) != "WAVE" {
+		return nil, f, errors.New("invalid wav header")
+	}
+
+	var pcm []byte
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size > len(body) {
+			size = len(body)
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, f, errors.New("invalid wav fmt chunk")
+			}
+			if tag := binary.LittleEndian.Uint16(body[0:2]); tag != 1 {
+				return nil, f, fmt.Errorf("unsupported wav encoding: %d", tag)
+			}
+			f.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			f.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			f.BitDepth = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			pcm = body[:size]
+		}
+
+		pos += 8 + size + size%2
+	}
+
+	if f.SampleRate == 0 {
+		return nil, f, errors.New("missing wav fmt chunk")
+	}
+	if f.BitDepth != 16 {
+		return nil, f, fmt.Errorf("unsupported bit depth: %d", f.BitDepth)
+	}
+
+	return decodePCM16(pcm, f.Channels), f, nil
+}
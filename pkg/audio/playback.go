@@ -0,0 +1,130 @@
+package audio
+
+import "math"
+
+// 回放检测的特征阈值与权重，权重之和达到 PlaybackThreshold 视为可疑
+// 单个特征都可能由设备或降噪引起，至少两个特征同时出现才会判定为可疑。
+const (
+	PlaybackThreshold = 0.6
+
+	// 采样全为 0 的帧占比：真实麦克风总有底噪，虚拟声卡注入或 TTS 文件常见完全静音
+	digitalSilenceRatio  = 0.1
+	digitalSilenceWeight = 0.5
+
+	// 4kHz 以上能量占比：经手机扬声器播放或低采样率 TTS 上采样后几乎没有高频
+	bandLimitedRatio  = 0.0005
+	bandLimitedWeight = 0.4
+
+	// 60-150Hz 能量占比：小扬声器无法还原低频，近讲麦克风通常有呼吸和近讲效应带来的低频
+	lowFrequencyRatio  = 0.002
+	lowFrequencyWeight = 0.3
+
+	playbackFrameMs = 64
+)
+
+// 回放特征
+const (
+	PlaybackDigitalSilence = "digital_silence"
+	PlaybackBandLimited    = "band_limited"
+	PlaybackNoLowFrequency = "no_low_frequency"
+)
+
+// Playback 扬声器回放、虚拟声卡注入等非现场录音的信号特征
+type Playback struct {
+	DigitalSilence float64  `json:"digital_silence"`
+	LowBandRatio   float64  `json:"low_band_ratio"`
+	HighBandRatio  float64  `json:"high_band_ratio"`
+	Score          float64  `json:"score"`
+	Reasons        []string `json:"reasons,omitempty"`
+}
+
+// Suspicious 是否像是回放的录音
+func (p Playback) Suspicious() bool {
+	return p.Score >= PlaybackThreshold
+}
+
+// AnalyzePlayback 分析音频中回放录音的典型特征
+func AnalyzePlayback(samples []int16, sampleRate int) Playback {
+	var p Playback
+	if sampleRate <= 0 {
+		return p
+	}
+
+	size := nextPow2(sampleRate * playbackFrameMs / 1000)
+	if len(samples) < size {
+		return p
+	}
+
+	window := hann(size)
+	buf := make([]complex128, size)
+	hzPerBin := float64(sampleRate) / float64(size)
+
+	var (
+		frames, zeroFrames  int
+		low, high, total    float64
+		lowFrom, lowTo, mid = bin(60, hzPerBin), bin(150, hzPerBin), bin(4000, hzPerBin)
+	)
+	for start := 0; start+size <= len(samples); start += size {
+		frame := samples[start : start+size]
+		frames++
+
+		if allZero(frame) {
+			zeroFrames++
+			continue
+		}
+		if Level(frame) < silenceThreshold {
+			continue
+		}
+
+		power := powerSpectrum(frame, window, buf)
+		for k := lowFrom; k < len(power); k++ {
+			total += power[k]
+			if k < lowTo {
+				low += power[k]
+			}
+			if k >= mid {
+				high += power[k]
+			}
+		}
+	}
+
+	p.DigitalSilence = round4(float64(zeroFrames) / float64(frames))
+	if total > 0 {
+		p.LowBandRatio = round4(low / total)
+		p.HighBandRatio = round4(high / total)
+	}
+
+	if p.DigitalSilence >= digitalSilenceRatio {
+		p.Score += digitalSilenceWeight
+		p.Reasons = append(p.Reasons, PlaybackDigitalSilence)
+	}
+	// 采样率不足 16kHz 时本来就没有 4kHz 以上的频率
+	if total > 0 && sampleRate >= DefaultSampleRate && high/total < bandLimitedRatio {
+		p.Score += bandLimitedWeight
+		p.Reasons = append(p.Reasons, PlaybackBandLimited)
+	}
+	if total > 0 && low/total < lowFrequencyRatio {
+		p.Score += lowFrequencyWeight
+		p.Reasons = append(p.Reasons, PlaybackNoLowFrequency)
+	}
+	p.Score = round4(p.Score)
+
+	return p
+}
+
+func allZero(samples []int16) bool {
+	for _, s := range samples {
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func bin(hz, hzPerBin float64) int {
+	return int(math.Round(hz / hzPerBin))
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
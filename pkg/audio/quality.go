@@ -0,0 +1,242 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// 电平表窗口长度（毫秒）
+	meterWindowMs = 100
+
+	// 低于该电平（dBFS）的窗口视为静音
+	silenceThreshold = -45.0
+
+	// 绝对值达到该采样值视为削波
+	clipLevel = math.MaxInt16 - 1
+
+	// 静音时 dBFS 的下限
+	minDBFS = -96.0
+)
+
+// Severity of a quality issue.
+const (
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// Quality 音频质量分析结果
+type Quality struct {
+	Format        Format    `json:"format"`
+	Duration      float64   `json:"duration"`
+	RMS           float64   `json:"rms_dbfs"`
+	Peak          float64   `json:"peak_dbfs"`
+	SpeechLevel   float64   `json:"speech_level_dbfs"`
+	NoiseFloor    float64   `json:"noise_floor_dbfs"`
+	SNR           float64   `json:"snr_db"`
+	ClippingRatio float64   `json:"clipping_ratio"`
+	SpeechRatio   float64   `json:"speech_ratio"`
+	Levels        []float64 `json:"levels"`
+}
+
+// Issue 音频质量问题
+type Issue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Level returns the RMS level of samples in dBFS.
+func Level(samples []int16) float64 {
+	if len(samples) == 0 {
+		return minDBFS
+	}
+
+	var sum float64
+	for _, s := range samples {
+		v := float64(s) / math.MaxInt16
+		sum += v * v
+	}
+
+	return toDBFS(math.Sqrt(sum / float64(len(samples))))
+}
+
+// PeakLevel returns the peak level of samples in dBFS.
+func PeakLevel(samples []int16) float64 {
+	var peak int
+	for _, s := range samples {
+		v := int(s)
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+
+	return toDBFS(float64(peak) / math.MaxInt16)
+}
+
+// Analyze 对整段音频做质量分析，samples 为 Decode 得到的单声道采样
+func Analyze(samples []int16, f Format) Quality {
+	q := Quality{
+		Format:     f,
+		RMS:        round1(Level(samples)),
+		Peak:       round1(PeakLevel(samples)),
+		NoiseFloor: minDBFS,
+	}
+	if f.SampleRate <= 0 || len(samples) == 0 {
+		return q
+	}
+
+	q.Duration = float64(len(samples)) / float64(f.SampleRate)
+
+	var clipped int
+	for _, s := range samples {
+		if s >= clipLevel || s <= -clipLevel {
+			clipped++
+		}
+	}
+	q.ClippingRatio = float64(clipped) / float64(len(samples))
+
+	// 按窗口计算电平表
+	window := f.SampleRate * meterWindowMs / 1000
+	if window <= 0 {
+		window = 1
+	}
+	for i := 0; i < len(samples); i += window {
+		end := i + window
+		if end > len(samples) {
+			end = len(samples)
+		}
+		q.Levels = append(q.Levels, round1(Level(samples[i:end])))
+	}
+
+	var speech []float64
+	for _, l := range q.Levels {
+		if l > silenceThreshold {
+			speech = append(speech, l)
+		}
+	}
+	q.SpeechRatio = float64(len(speech)) / float64(len(q.Levels))
+	if len(speech) > 0 {
+		q.SpeechLevel = percentile(speech, 0.5)
+	} else {
+		q.SpeechLevel = minDBFS
+	}
+
+	// 取最安静的 10% 窗口作为底噪估计
+	q.NoiseFloor = percentile(q.Levels, 0.1)
+	q.SNR = round1(q.SpeechLevel - q.NoiseFloor)
+
+	return q
+}
+
+// Check 根据质量分析结果给出问题列表
+// declared 为客户端声明的格式，received 为实际接收速率（采样/秒，未知时传 0）。
+func Check(q Quality, declared Format, received float64) []Issue {
+	var issues []Issue
+
+	switch {
+	case declared.SampleRate > 0 && q.Format.SampleRate != declared.SampleRate:
+		issues = append(issues, Issue{
+			Code:     "sample_rate_mismatch",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("audio header says %dHz but %dHz was declared", q.Format.SampleRate, declared.SampleRate),
+		})
+	case q.Format.SampleRate != DefaultSampleRate:
+		issues = append(issues, Issue{
+			Code:     "sample_rate_mismatch",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("audio is %dHz, engine requires %dHz", q.Format.SampleRate, DefaultSampleRate),
+		})
+	}
+	if q.Format.BitDepth != DefaultBitDepth || q.Format.Channels != DefaultChannels {
+		issues = append(issues, Issue{
+			Code:     "unsupported_format",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("audio is %dbit/%dch, engine requires %dbit mono", q.Format.BitDepth, q.Format.Channels, DefaultBitDepth),
+		})
+	}
+
+	// 实时推流时，接收速率应接近声明的采样率，偏差过大说明客户端重采样有误
+	if received > 0 && q.Format.SampleRate > 0 {
+		ratio := received / float64(q.Format.SampleRate)
+		if ratio < 0.75 || ratio > 1.25 {
+			issues = append(issues, Issue{
+				Code:     "sample_rate_suspect",
+				Severity: SeverityWarn,
+				Message:  fmt.Sprintf("received %.0f samples/s, expected about %d", received, q.Format.SampleRate),
+			})
+		}
+	}
+
+	if q.Duration < 1 {
+		issues = append(issues, Issue{
+			Code:     "too_short",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("recording is %.1fs, at least 1s is required", q.Duration),
+		})
+	}
+
+	switch {
+	case q.SpeechRatio == 0:
+		issues = append(issues, Issue{
+			Code:     "no_speech",
+			Severity: SeverityError,
+			Message:  "no speech detected, check that the microphone is not muted",
+		})
+	case q.SpeechLevel < -35:
+		issues = append(issues, Issue{
+			Code:     "too_quiet",
+			Severity: SeverityWarn,
+			Message:  fmt.Sprintf("speech level %.1fdBFS is low, move closer to the microphone", q.SpeechLevel),
+		})
+	}
+
+	if q.ClippingRatio > 0.01 {
+		issues = append(issues, Issue{
+			Code:     "clipping",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%.1f%% of samples are clipped, lower the input gain", q.ClippingRatio*100),
+		})
+	}
+
+	if q.SpeechRatio > 0 && q.SNR < 15 {
+		issues = append(issues, Issue{
+			Code:     "noisy",
+			Severity: SeverityWarn,
+			Message:  fmt.Sprintf("signal-to-noise ratio is %.1fdB, find a quieter place", q.SNR),
+		})
+	}
+
+	return issues
+}
+
+// Passed 不存在 error 级别问题即视为通过
+func Passed(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+func toDBFS(v float64) float64 {
+	if v <= 0 {
+		return minDBFS
+	}
+	return math.Max(20*math.Log10(v), minDBFS)
+}
+
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted[int(p*float64(len(sorted)-1))]
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
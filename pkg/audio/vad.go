@@ -0,0 +1,73 @@
+package audio
+
+const (
+	// VAD 分析帧长（毫秒）
+	vadFrameMs = 20
+
+	// 语音之后持续静音达到该时长视为一次停顿（毫秒）
+	vadMinPauseMs = 500
+)
+
+// VAD 基于能量的语音端点检测，用于在连续音频流中寻找句间停顿
+type VAD struct {
+	frameSize int
+	minPause  int
+	threshold float64
+
+	pending []int16
+	silent  int
+	speech  int
+	paused  bool
+}
+
+// NewVAD
+func NewVAD(sampleRate int) *VAD {
+	if sampleRate <= 0 {
+		sampleRate = DefaultSampleRate
+	}
+
+	return &VAD{
+		frameSize: sampleRate * vadFrameMs / 1000,
+		minPause:  vadMinPauseMs / vadFrameMs,
+		threshold: silenceThreshold,
+	}
+}
+
+// Write 输入采样，本次输入中检测到语音之后的停顿时返回 true
+// 每段语音之后的停顿只报告一次。
+func (v *VAD) Write(samples []int16) bool {
+	v.pending = append(v.pending, samples...)
+
+	var pause bool
+	for len(v.pending) >= v.frameSize {
+		frame := v.pending[:v.frameSize]
+		v.pending = v.pending[v.frameSize:]
+
+		if Level(frame) > v.threshold {
+			v.speech++
+			v.silent = 0
+			v.paused = false
+			continue
+		}
+
+		v.silent++
+		if v.speech > 0 && !v.paused && v.silent >= v.minPause {
+			v.paused = true
+			pause = true
+		}
+	}
+
+	return pause
+}
+
+// SpeechDuration 自上次 Reset 以来检测到的语音时长（秒）
+func (v *VAD) SpeechDuration() float64 {
+	return float64(v.speech*vadFrameMs) / 1000
+}
+
+// Reset 清空语音时长统计，用于开始新的一段
+func (v *VAD) Reset() {
+	v.speech = 0
+	v.silent = 0
+	v.paused = false
+}
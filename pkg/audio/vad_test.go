@@ -0,0 +1,72 @@
+package audio
+
+import "testing"
+
+// silence 返回指定时长的静音
+func silence(seconds float64) []int16 {
+	return make([]int16, int(seconds*testSampleRate))
+}
+
+func concat(parts ...[]int16) []int16 {
+	var out []int16
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestVAD(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []int16
+		pauses  int
+		speech  float64 // 语音时长（秒）
+	}{
+		{"silence only", silence(2), 0, 0},
+		{"speech without pause", tones(1, 1), 0, 1},
+		{"short pause", concat(tones(1, 1), silence(0.3), tones(2, 1)), 0, 2},
+		{"pause after speech", concat(tones(1, 1), silence(0.6)), 1, 1},
+		{"long pause reported once", concat(tones(1, 1), silence(3)), 1, 1},
+		{"two pauses", concat(tones(1, 1), silence(0.6), tones(2, 0.5), silence(0.6)), 2, 1.5},
+		{"leading silence", concat(silence(1), tones(1, 0.5)), 0, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 按 100ms 分块写入，块边界与分析帧不对齐
+			v := NewVAD(testSampleRate)
+			chunk := testSampleRate/10 + 7
+			var pauses int
+			for i := 0; i < len(tt.samples); i += chunk {
+				end := i + chunk
+				if end > len(tt.samples) {
+					end = len(tt.samples)
+				}
+				if v.Write(tt.samples[i:end]) {
+					pauses++
+				}
+			}
+
+			if pauses != tt.pauses {
+				t.Errorf("pauses = %d, want %d", pauses, tt.pauses)
+			}
+			if got := v.SpeechDuration(); got < tt.speech-0.05 || got > tt.speech+0.05 {
+				t.Errorf("SpeechDuration() = %v, want %v", got, tt.speech)
+			}
+		})
+	}
+}
+
+func TestVADReset(t *testing.T) {
+	v := NewVAD(testSampleRate)
+	v.Write(tones(1, 1))
+	v.Reset()
+
+	if got := v.SpeechDuration(); got != 0 {
+		t.Errorf("SpeechDuration() after Reset = %v, want 0", got)
+	}
+	// Reset 后没有新的语音，静音不视为停顿
+	if v.Write(silence(1)) {
+		t.Error("Write(silence) after Reset = true, want false")
+	}
+}
@@ -0,0 +1,328 @@
+package classroom
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/content"
+	"lingolift/pkg/store"
+)
+
+var (
+	classes     *store.Table[Class]
+	members     *store.Table[Member]
+	assignments *store.Table[Assignment]
+	submissions *store.Table[Submission]
+	attempts    *store.Table[Attempt]
+)
+
+// Class 班级，由创建者（教师）管理
+// 用户 ID 只在租户内唯一，班级、成员和作业只在创建班级的租户内可见。
+type Class struct {
+	ID        string    `json:"id"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Name      string    `json:"name"`
+	TeacherID string    `json:"teacher_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 成员状态：教师邀请后需由学生本人接受才成为班级成员
+const (
+	MemberInvited = "invited"
+	MemberJoined  = "joined"
+)
+
+// Member 班级成员（学生）
+// 只有已加入的成员计入班级：教师才能查看其评测数据、实时监听其评测，学生才能提交作业。
+type Member struct {
+	ID        string    `json:"-"`
+	Tenant    string    `json:"tenant,omitempty"`
+	ClassID   string    `json:"class_id"`
+	UserID    string    `json:"user_id"`
+	Status    string    `json:"status"`
+	InvitedAt time.Time `json:"invited_at"`
+	JoinedAt  time.Time `json:"joined_at,omitempty"`
+}
+
+// Assignment 布置给班级的一组内容条目
+type Assignment struct {
+	ID        string    `json:"id"`
+	ClassID   string    `json:"class_id"`
+	Title     string    `json:"title"`
+	ItemIDs   []string  `json:"item_ids"`
+	DueAt     time.Time `json:"due_at"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Exam 考试模式配置，为空时为普通作业
+	Exam *ExamPolicy `json:"exam,omitempty"`
+}
+
+// Init 打开班级相关的表
+func Init(db *store.DB) (err error) {
+	if classes, err = store.NewTable[Class](db, "classes"); err != nil {
+		return err
+	}
+	if members, err = store.NewTable[Member](db, "class_members"); err != nil {
+		return err
+	}
+	if assignments, err = store.NewTable[Assignment](db, "assignments"); err != nil {
+		return err
+	}
+	if submissions, err = store.NewTable[Submission](db, "submissions"); err != nil {
+		return err
+	}
+	attempts, err = store.NewTable[Attempt](db, "exam_attempts")
+	return err
+}
+
+// CreateClass 在租户中创建班级
+func CreateClass(tenant, name, teacherID string) (*Class, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return nil, errno.ErrMissingParameter.WithFmt("name")
+	}
+
+	c := &Class{
+		ID:        store.NewID(),
+		Tenant:    tenant,
+		Name:      name,
+		TeacherID: teacherID,
+		CreatedAt: time.Now(),
+	}
+	if err := classes.Put(c.ID, c); err != nil {
+		return nil, errno.ErrDatabase.WithRawErr(err)
+	}
+	return c, nil
+}
+
+// ListClasses 返回教师在租户中创建的班级
+func ListClasses(tenant, teacherID string) []*Class {
+	list := classes.List(func(c *Class) bool {
+		return c.Tenant == tenant && c.TeacherID == teacherID
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// GetClass 查询租户的班级，其他租户的班级视为不存在
+func GetClass(tenant, id string) (*Class, error) {
+	c, err := classes.Get(id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && c.Tenant != tenant) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("class %q", id))
+	}
+	return c, err
+}
+
+// TeacherClass 查询租户的班级并检查调用者是否为该班级的教师
+func TeacherClass(tenant, id, teacherID string) (*Class, error) {
+	c, err := GetClass(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.TeacherID != teacherID {
+		return nil, *errno.ErrPermissionDenied
+	}
+	return c, nil
+}
+
+// AddMember 邀请班级所在租户的学生加入班级，学生接受后才成为成员；已邀请或已在班级中时直接返回
+func AddMember(c *Class, userID string) (*Member, error) {
+	userID = strings.TrimSpace(userID)
+	if len(userID) == 0 {
+		return nil, errno.ErrMissingParameter.WithFmt("user_id")
+	}
+
+	id := memberID(c.ID, userID)
+	if m, err := members.Get(id); err == nil {
+		return m, nil
+	}
+
+	m := &Member{
+		ID:        id,
+		Tenant:    c.Tenant,
+		ClassID:   c.ID,
+		UserID:    userID,
+		Status:    MemberInvited,
+		InvitedAt: time.Now(),
+	}
+	if err := members.Put(id, m); err != nil {
+		return nil, errno.ErrDatabase.WithRawErr(err)
+	}
+	return m, nil
+}
+
+// AcceptInvitation 租户中的学生接受班级邀请，没有邀请时返回不存在
+func AcceptInvitation(tenant, classID, userID string) (*Member, error) {
+	id := memberID(classID, userID)
+	m, err := members.Get(id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && m.Tenant != tenant) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("invitation to class %q", classID))
+	}
+	if err != nil {
+		return nil, errno.ErrDatabase.WithRawErr(err)
+	}
+	if m.Joined() {
+		return m, nil
+	}
+
+	m.Status = MemberJoined
+	m.JoinedAt = time.Now()
+	if err = members.Put(id, m); err != nil {
+		return nil, errno.ErrDatabase.WithRawErr(err)
+	}
+	return m, nil
+}
+
+// ListInvitations 返回租户中的学生尚未接受的班级邀请
+func ListInvitations(tenant, userID string) []*Member {
+	list := members.List(func(m *Member) bool {
+		return m.Tenant == tenant && m.UserID == userID && !m.Joined()
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].InvitedAt.Before(list[j].InvitedAt)
+	})
+	return list
+}
+
+// Joined 学生是否已接受邀请
+func (m *Member) Joined() bool {
+	return m.Status == MemberJoined
+}
+
+// RemoveMember 把租户中的学生移出班级
+func RemoveMember(tenant, classID, userID string) error {
+	id := memberID(classID, userID)
+	m, err := members.Get(id)
+	if err == nil && m.Tenant != tenant {
+		err = store.ErrNotFound
+	}
+	if err == nil {
+		err = members.Delete(id)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("member %q", userID))
+	}
+	if err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// ListMembers 返回班级成员和待接受的邀请，按邀请时间排序
+func ListMembers(classID string) []*Member {
+	list := members.List(func(m *Member) bool {
+		return m.ClassID == classID
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].InvitedAt.Before(list[j].InvitedAt)
+	})
+	return list
+}
+
+// MemberClasses 返回租户中的学生已加入的班级 ID
+func MemberClasses(tenant, userID string) []string {
+	if len(userID) == 0 {
+		return nil
+	}
+
+	var ids []string
+	for _, m := range members.List(func(m *Member) bool {
+		return m.Tenant == tenant && m.UserID == userID && m.Joined()
+	}) {
+		ids = append(ids, m.ClassID)
+	}
+	return ids
+}
+
+// IsMember 学生是否已加入班级，调用者需先确认班级属于学生所在的租户
+func IsMember(classID, userID string) bool {
+	m, err := members.Get(memberID(classID, userID))
+	return err == nil && m.Joined()
+}
+
+// IsTeacherOf 租户中的教师是否教授学生已加入的某个班级
+func IsTeacherOf(tenant, teacherID, userID string) bool {
+	for _, id := range MemberClasses(tenant, userID) {
+		if _, err := TeacherClass(tenant, id, teacherID); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAssignment 布置作业，条目必须存在于租户的内容库中
+func CreateAssignment(tenant string, a *Assignment) error {
+	a.Title = strings.TrimSpace(a.Title)
+	if len(a.Title) == 0 {
+		return errno.ErrMissingParameter.WithFmt("title")
+	}
+	if len(a.ItemIDs) == 0 {
+		return errno.ErrMissingParameter.WithFmt("item_ids")
+	}
+	if a.DueAt.IsZero() {
+		return errno.ErrMissingParameter.WithFmt("due_at")
+	}
+	if a.Exam != nil {
+		if err := a.Exam.Check(); err != nil {
+			return err
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, id := range a.ItemIDs {
+		if seen[id] {
+			return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf("item %q is assigned more than once.", id))
+		}
+		seen[id] = true
+		if _, err := content.TenantItem(tenant, id); err != nil {
+			return err
+		}
+	}
+
+	a.ID = store.NewID()
+	a.CreatedAt = time.Now()
+	if err := assignments.Put(a.ID, a); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// GetAssignment 查询作业
+func GetAssignment(id string) (*Assignment, error) {
+	a, err := assignments.Get(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("assignment %q", id))
+	}
+	return a, err
+}
+
+// ListAssignments 返回班级的作业，按截止时间排序
+func ListAssignments(classID string) []*Assignment {
+	list := assignments.List(func(a *Assignment) bool {
+		return a.ClassID == classID
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].DueAt.Before(list[j].DueAt)
+	})
+	return list
+}
+
+// HasItem 条目是否属于该作业
+func (a *Assignment) HasItem(itemID string) bool {
+	for _, id := range a.ItemIDs {
+		if id == itemID {
+			return true
+		}
+	}
+	return false
+}
+
+func memberID(classID, userID string) string {
+	return classID + ":" + userID
+}
@@ -0,0 +1,107 @@
+package classroom
+
+import "testing"
+
+func TestInvitation(t *testing.T) {
+	class, err := CreateClass("", "class", "teacher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = AddMember(class, "student"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未接受邀请前教师不能查看学生数据，学生也不计入班级
+	check := func(stage string, joined, invited bool) {
+		t.Helper()
+		if got := IsMember(class.ID, "student"); got != joined {
+			t.Errorf("%s: IsMember() = %v, want %v", stage, got, joined)
+		}
+		if got := IsTeacherOf("", "teacher", "student"); got != joined {
+			t.Errorf("%s: IsTeacherOf() = %v, want %v", stage, got, joined)
+		}
+		if got := len(MemberClasses("", "student")) > 0; got != joined {
+			t.Errorf("%s: MemberClasses() not empty = %v, want %v", stage, got, joined)
+		}
+		if got := len(ListInvitations("", "student")) > 0; got != invited {
+			t.Errorf("%s: ListInvitations() not empty = %v, want %v", stage, got, invited)
+		}
+	}
+
+	check("invited", false, true)
+	if _, err = AcceptInvitation("", class.ID, "other"); err == nil {
+		t.Error("AcceptInvitation() without invitation succeeded")
+	}
+	if IsTeacherOf("", "teacher", "other") {
+		t.Error("IsTeacherOf() = true for a user who was never invited")
+	}
+
+	m, err := AcceptInvitation("", class.ID, "student")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Joined() || m.JoinedAt.IsZero() {
+		t.Errorf("AcceptInvitation() = %+v, want joined", m)
+	}
+	check("joined", true, false)
+
+	// 重复邀请不会把已加入的成员改回邀请状态
+	if m, err = AddMember(class, "student"); err != nil || !m.Joined() {
+		t.Errorf("AddMember() = %+v, %v, want the joined member", m, err)
+	}
+
+	if err = RemoveMember("", class.ID, "student"); err != nil {
+		t.Fatal(err)
+	}
+	check("removed", false, false)
+}
+
+func TestTenantScope(t *testing.T) {
+	class, err := CreateClass("school_a", "class", "teacher_a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = AddMember(class, "student"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他租户的同名用户不能接受邀请，也看不到班级
+	if _, err = AcceptInvitation("school_b", class.ID, "student"); err == nil {
+		t.Error("AcceptInvitation() from another tenant succeeded")
+	}
+	if got := ListInvitations("school_b", "student"); len(got) > 0 {
+		t.Errorf("ListInvitations() from another tenant = %d invitations, want none", len(got))
+	}
+	if _, err = AcceptInvitation("school_a", class.ID, "student"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tenant  string
+		visible bool
+	}{
+		{"school_a", true},
+		{"school_b", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, err := TeacherClass(tt.tenant, class.ID, "teacher_a"); (err == nil) != tt.visible {
+			t.Errorf("TeacherClass(%q) = %v, want visible %v", tt.tenant, err, tt.visible)
+		}
+		if got := IsTeacherOf(tt.tenant, "teacher_a", "student"); got != tt.visible {
+			t.Errorf("IsTeacherOf(%q) = %v, want %v", tt.tenant, got, tt.visible)
+		}
+		if got := len(MemberClasses(tt.tenant, "student")) > 0; got != tt.visible {
+			t.Errorf("MemberClasses(%q) not empty = %v, want %v", tt.tenant, got, tt.visible)
+		}
+		if got := len(ListClasses(tt.tenant, "teacher_a")) > 0; got != tt.visible {
+			t.Errorf("ListClasses(%q) not empty = %v, want %v", tt.tenant, got, tt.visible)
+		}
+		if err := RemoveMember(tt.tenant, "missing", "student"); err == nil {
+			t.Errorf("RemoveMember(%q) of a missing class succeeded", tt.tenant)
+		}
+	}
+	if err = RemoveMember("school_b", class.ID, "student"); err == nil {
+		t.Error("RemoveMember() from another tenant succeeded")
+	}
+}
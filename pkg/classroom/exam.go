@@ -0,0 +1,106 @@
+package classroom
+
+import (
+	"fmt"
+	"time"
+
+	"lingolift/errno"
+)
+
+// ExamPolicy 作业的考试模式配置
+// 考试模式下学生只能在时间窗口内评测，每个条目的评测次数受限，评分配置固定，
+// 评测过程中不返回中间结果，最终记录签名后不可修改。
+type ExamPolicy struct {
+	// MaxAttempts 每个条目最多评测次数，0 表示不限
+	MaxAttempts int `json:"max_attempts"`
+
+	// StartAt、EndAt 允许开始评测的时间窗口，为零值时不限制
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+
+	// ScoringProfile 考试使用的评分配置，忽略客户端指定的配置
+	ScoringProfile string `json:"scoring_profile,omitempty"`
+}
+
+// Attempt 学生对考试条目的评测次数，在评测开始时计数
+type Attempt struct {
+	ID            string    `json:"-"`
+	AssignmentID  string    `json:"assignment_id"`
+	UserID        string    `json:"user_id"`
+	ItemID        string    `json:"item_id"`
+	Count         int       `json:"count"`
+	LastStartedAt time.Time `json:"last_started_at"`
+}
+
+// Check 校验考试配置
+func (p *ExamPolicy) Check() error {
+	if p.MaxAttempts < 0 {
+		return errno.ErrInvalidParameterValue.WithFmt("exam.max_attempts must not be negative.")
+	}
+	if !p.StartAt.IsZero() && !p.EndAt.IsZero() && !p.EndAt.After(p.StartAt) {
+		return errno.ErrInvalidParameterValue.WithFmt("exam.end_at must be after exam.start_at.")
+	}
+	return nil
+}
+
+// StartAttempt 考试模式下开始一次评测：检查时间窗口和剩余次数，并计入一次评测
+// 返回本次是第几次评测。非考试作业直接返回 0。
+func StartAttempt(a *Assignment, userID, itemID string, now time.Time) (int, error) {
+	p := a.Exam
+	if p == nil {
+		return 0, nil
+	}
+
+	if !p.StartAt.IsZero() && now.Before(p.StartAt) {
+		return 0, errno.ErrOutOfTimeWindow.WithFmt(fmt.Sprintf(
+			"exam %q opens at %s.", a.ID, p.StartAt.Format(time.RFC3339)))
+	}
+	if !p.EndAt.IsZero() && now.After(p.EndAt) {
+		return 0, errno.ErrOutOfTimeWindow.WithFmt(fmt.Sprintf(
+			"exam %q closed at %s.", a.ID, p.EndAt.Format(time.RFC3339)))
+	}
+
+	submitMu.Lock()
+	defer submitMu.Unlock()
+
+	id := a.ID + ":" + userID + ":" + itemID
+	attempt := &Attempt{ID: id, AssignmentID: a.ID, UserID: userID, ItemID: itemID}
+	if prev, err := attempts.Get(id); err == nil {
+		attempt = prev
+	}
+
+	if p.MaxAttempts > 0 && attempt.Count >= p.MaxAttempts {
+		return 0, errno.ErrExceedsLimit.WithFmt(fmt.Sprintf(
+			"item %q allows at most %d attempts.", itemID, p.MaxAttempts))
+	}
+
+	attempt.Count++
+	attempt.LastStartedAt = now
+	if err := attempts.Put(id, attempt); err != nil {
+		return 0, errno.ErrDatabase.WithRawErr(err)
+	}
+
+	return attempt.Count, nil
+}
+
+// CancelAttempt 撤销 StartAttempt 计入的一次评测，用于上游启动失败、评测未真正开始的情况
+func CancelAttempt(a *Assignment, userID, itemID string) error {
+	if a.Exam == nil {
+		return nil
+	}
+
+	submitMu.Lock()
+	defer submitMu.Unlock()
+
+	id := a.ID + ":" + userID + ":" + itemID
+	attempt, err := attempts.Get(id)
+	if err != nil || attempt.Count == 0 {
+		return nil
+	}
+
+	attempt.Count--
+	if err = attempts.Put(id, attempt); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
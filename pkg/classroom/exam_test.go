@@ -0,0 +1,73 @@
+package classroom
+
+import (
+	"testing"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/store"
+)
+
+func TestMain(m *testing.M) {
+	db, err := store.Open(nil)
+	if err != nil {
+		panic(err)
+	}
+	if err = Init(db); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestStartAttempt(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	a := &Assignment{ID: "exam", Exam: &ExamPolicy{
+		MaxAttempts: 2,
+		StartAt:     now.Add(-time.Hour),
+		EndAt:       now.Add(time.Hour),
+	}}
+
+	tests := []struct {
+		name   string
+		user   string
+		now    time.Time
+		cancel bool // 开始后撤销本次评测
+		count  int
+		code   string
+	}{
+		{"before window", "u1", now.Add(-2 * time.Hour), false, 0, errno.ErrOutOfTimeWindow.Code},
+		{"after window", "u1", now.Add(2 * time.Hour), false, 0, errno.ErrOutOfTimeWindow.Code},
+		{"first attempt", "u1", now, false, 1, ""},
+		{"cancelled attempt", "u1", now, true, 2, ""},
+		{"second attempt", "u1", now, false, 2, ""},
+		{"exceeds limit", "u1", now, false, 0, errno.ErrExceedsLimit.Code},
+		{"other user", "u2", now, false, 1, ""},
+	}
+
+	for _, tt := range tests {
+		count, err := StartAttempt(a, tt.user, "item", tt.now)
+		if len(tt.code) > 0 {
+			if e, ok := err.(errno.Err); !ok || e.Code != tt.code {
+				t.Errorf("%s: StartAttempt() error = %v, want %s", tt.name, err, tt.code)
+			}
+			continue
+		}
+		if err != nil || count != tt.count {
+			t.Errorf("%s: StartAttempt() = %d, %v, want %d", tt.name, count, err, tt.count)
+		}
+		if tt.cancel {
+			if err = CancelAttempt(a, tt.user, "item"); err != nil {
+				t.Errorf("%s: CancelAttempt() = %v", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestStartAttemptWithoutExam(t *testing.T) {
+	a := &Assignment{ID: "homework"}
+	for i := 0; i < 3; i++ {
+		if count, err := StartAttempt(a, "u1", "item", time.Now()); err != nil || count != 0 {
+			t.Errorf("StartAttempt() = %d, %v, want 0", count, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package classroom
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/record"
+)
+
+// 同一学生同一条目的多次提交串行更新
+var submitMu sync.Mutex
+
+// Submission 学生对作业中单个条目的提交，保留最近一次和最高分的评测记录
+type Submission struct {
+	ID           string    `json:"-"`
+	AssignmentID string    `json:"assignment_id"`
+	UserID       string    `json:"user_id"`
+	ItemID       string    `json:"item_id"`
+	Attempts     int       `json:"attempts"`
+	SessionID    string    `json:"session_id"`
+	Score        float64   `json:"score"`
+	Level        string    `json:"level,omitempty"`
+	BestScore    float64   `json:"best_score"`
+	BestSession  string    `json:"best_session_id"`
+	Late         bool      `json:"late"`
+	Suspicious   bool      `json:"suspicious"` // 任一次提交疑似作弊
+	SubmittedAt  time.Time `json:"submitted_at"`
+}
+
+// StudentProgress 学生的作业完成情况
+type StudentProgress struct {
+	UserID       string        `json:"user_id"`
+	Completed    int           `json:"completed"`
+	Total        int           `json:"total"`
+	AverageScore float64       `json:"average_score"`
+	Late         bool          `json:"late"`
+	Suspicious   bool          `json:"suspicious"`
+	Submissions  []*Submission `json:"submissions"`
+}
+
+// CheckSubmission 检查学生是否可以提交该作业中的条目
+func CheckSubmission(tenant, assignmentID, userID, itemID string) (*Assignment, error) {
+	a, err := GetAssignment(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = GetClass(tenant, a.ClassID); err != nil {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("assignment %q", assignmentID))
+	}
+	if len(userID) == 0 {
+		return nil, errno.ErrMissingParameter.WithFmt("user_id")
+	}
+	if !IsMember(a.ClassID, userID) {
+		return nil, *errno.ErrPermissionDenied
+	}
+	if !a.HasItem(itemID) {
+		return nil, errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"item %q is not part of assignment %q.", itemID, assignmentID))
+	}
+	return a, nil
+}
+
+// Submit 根据评测记录更新作业提交
+func Submit(assignmentID string, r *record.Record) error {
+	if len(assignmentID) == 0 || r.Result == nil {
+		return nil
+	}
+
+	a, err := CheckSubmission(r.Tenant, assignmentID, r.UserID, r.ItemID)
+	if err != nil {
+		return err
+	}
+
+	submitMu.Lock()
+	defer submitMu.Unlock()
+
+	id := assignmentID + ":" + r.UserID + ":" + r.ItemID
+	s := &Submission{ID: id, AssignmentID: assignmentID, UserID: r.UserID, ItemID: r.ItemID}
+	if prev, err := submissions.Get(id); err == nil {
+		s = prev
+	}
+
+	score := resultScore(r)
+	s.Attempts++
+	s.SessionID = r.ID
+	s.Score = score
+	s.Level = r.Result.Level
+	s.SubmittedAt = r.CreatedAt
+	s.Late = r.CreatedAt.After(a.DueAt)
+	s.Suspicious = s.Suspicious || r.Result.Suspicious
+	if s.Attempts == 1 || score > s.BestScore {
+		s.BestScore = score
+		s.BestSession = r.ID
+	}
+
+	if err = submissions.Put(id, s); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// Progress 返回班级每个学生的作业完成情况，平均分按各条目最高分计算，未完成的条目按 0 分计入
+func Progress(a *Assignment) []*StudentProgress {
+	byUser := map[string][]*Submission{}
+	for _, s := range submissions.List(func(s *Submission) bool {
+		return s.AssignmentID == a.ID
+	}) {
+		byUser[s.UserID] = append(byUser[s.UserID], s)
+	}
+
+	var list []*StudentProgress
+	for _, m := range ListMembers(a.ClassID) {
+		if !m.Joined() {
+			continue
+		}
+		p := &StudentProgress{
+			UserID:      m.UserID,
+			Total:       len(a.ItemIDs),
+			Submissions: []*Submission{},
+		}
+		var total float64
+		for _, s := range byUser[m.UserID] {
+			if !a.HasItem(s.ItemID) {
+				continue
+			}
+			p.Completed++
+			p.Late = p.Late || s.Late
+			p.Suspicious = p.Suspicious || s.Suspicious
+			total += s.BestScore
+			p.Submissions = append(p.Submissions, s)
+		}
+		if p.Total > 0 {
+			p.AverageScore = round(total / float64(p.Total))
+		}
+		sort.SliceStable(p.Submissions, func(i, j int) bool {
+			return itemIndex(a, p.Submissions[i].ItemID) < itemIndex(a, p.Submissions[j].ItemID)
+		})
+		list = append(list, p)
+	}
+	return list
+}
+
+// GradesCSV 导出成绩：每个学生一行，每个条目一列（最高分，未完成为空）
+func GradesCSV(a *Assignment) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"user_id"}
+	header = append(header, a.ItemIDs...)
+	header = append(header, "completed", "total", "average_score", "late", "suspicious")
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, p := range Progress(a) {
+		scores := map[string]float64{}
+		for _, s := range p.Submissions {
+			scores[s.ItemID] = s.BestScore
+		}
+
+		row := []string{p.UserID}
+		for _, id := range a.ItemIDs {
+			if score, ok := scores[id]; ok {
+				row = append(row, formatScore(score))
+			} else {
+				row = append(row, "")
+			}
+		}
+		row = append(row,
+			strconv.Itoa(p.Completed),
+			strconv.Itoa(p.Total),
+			formatScore(p.AverageScore),
+			strconv.FormatBool(p.Late),
+			strconv.FormatBool(p.Suspicious),
+		)
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// resultScore 优先使用评分配置加权后的得分
+func resultScore(r *record.Record) float64 {
+	if r.Result.Score > 0 {
+		return r.Result.Score
+	}
+	return round(r.Result.OverallScore)
+}
+
+func itemIndex(a *Assignment, itemID string) int {
+	for i, id := range a.ItemIDs {
+		if id == itemID {
+			return i
+		}
+	}
+	return len(a.ItemIDs)
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 2, 64)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,168 @@
+package content
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"lingolift/errno"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 导入导出格式
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// csvHeader CSV 列，多个标签以 ; 分隔，eval_mode 为空表示自动检测
+var csvHeader = []string{"id", "ref_text", "eval_mode", "server_engine_type", "phonetic_hints", "tags", "difficulty"}
+
+// ContentType 返回导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatYAML:
+		return "application/x-yaml"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// EncodeItems 按格式导出条目
+func EncodeItems(format string, list []*Item) ([]byte, error) {
+	if list == nil {
+		list = []*Item{}
+	}
+
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(list, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(list)
+	case FormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		for _, it := range list {
+			evalMode := ""
+			if it.EvalMode != nil {
+				evalMode = strconv.FormatInt(*it.EvalMode, 10)
+			}
+			if err := w.Write([]string{
+				it.ID,
+				it.RefText,
+				evalMode,
+				it.ServerEngineType,
+				it.PhoneticHints,
+				strings.Join(it.Tags, ";"),
+				strconv.Itoa(it.Difficulty),
+			}); err != nil {
+				return nil, err
+			}
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+
+	return nil, unsupportedFormat(format)
+}
+
+// DecodeItems 按格式解析导入的条目
+func DecodeItems(format string, data []byte) ([]*Item, error) {
+	var list []*Item
+
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, errno.ErrInvalidParameter.WithRawErr(err)
+		}
+	case FormatYAML:
+		if err := yaml.UnmarshalStrict(data, &list); err != nil {
+			return nil, errno.ErrInvalidParameter.WithRawErr(err)
+		}
+	case FormatCSV:
+		return decodeCSV(data)
+	default:
+		return nil, unsupportedFormat(format)
+	}
+
+	return list, nil
+}
+
+// decodeCSV 按表头解析 CSV，列顺序不限，缺少的列按空值处理
+func decodeCSV(data []byte) ([]*Item, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, errno.ErrInvalidParameter.WithRawErr(err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["ref_text"]; !ok {
+		return nil, errno.ErrMissingParameter.WithFmt("ref_text column")
+	}
+
+	var list []*Item
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errno.ErrInvalidParameter.WithRawErr(err)
+		}
+
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		it := &Item{
+			ID:               get("id"),
+			RefText:          get("ref_text"),
+			ServerEngineType: get("server_engine_type"),
+			PhoneticHints:    get("phonetic_hints"),
+		}
+		if v := get("eval_mode"); len(v) > 0 {
+			evalMode, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf("line %d: invalid eval_mode %q.", line, v))
+			}
+			it.EvalMode = &evalMode
+		}
+		if v := get("difficulty"); len(v) > 0 {
+			if it.Difficulty, err = strconv.Atoi(v); err != nil {
+				return nil, errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf("line %d: invalid difficulty %q.", line, v))
+			}
+		}
+		for _, tag := range strings.Split(get("tags"), ";") {
+			if tag = strings.TrimSpace(tag); len(tag) > 0 {
+				it.Tags = append(it.Tags, tag)
+			}
+		}
+		list = append(list, it)
+	}
+
+	return list, nil
+}
+
+func unsupportedFormat(format string) error {
+	return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+		"format %q is not supported, use %s, %s or %s.", format, FormatJSON, FormatYAML, FormatCSV))
+}
@@ -0,0 +1,304 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/store"
+)
+
+// 难度取值范围，0 表示未设置
+const (
+	MinDifficulty = 0
+	MaxDifficulty = 5
+)
+
+var (
+	lessons *store.Table[Lesson]
+	items   *store.Table[Item]
+)
+
+// Lesson 课程，包含一组有序的评测条目，只有作者可以修改课程和条目
+// 课程属于创建时的租户，其他租户不可见。
+type Lesson struct {
+	ID          string    `json:"id" yaml:"id"`
+	Tenant      string    `json:"tenant,omitempty" yaml:"-"`
+	AuthorID    string    `json:"author_id" yaml:"-"`
+	Title       string    `json:"title" yaml:"title"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at" yaml:"-"`
+	UpdatedAt   time.Time `json:"updated_at" yaml:"-"`
+}
+
+// Item 评测条目
+// EvalMode 为空时根据参考文本自动检测，ServerEngineType 为空时使用检测到的语言对应的引擎。
+type Item struct {
+	ID               string    `json:"id" yaml:"id,omitempty"`
+	LessonID         string    `json:"lesson_id" yaml:"-"`
+	Position         int       `json:"position" yaml:"-"`
+	RefText          string    `json:"ref_text" yaml:"ref_text"`
+	EvalMode         *int64    `json:"eval_mode" yaml:"eval_mode,omitempty"`
+	ServerEngineType string    `json:"server_engine_type" yaml:"server_engine_type,omitempty"`
+	PhoneticHints    string    `json:"phonetic_hints,omitempty" yaml:"phonetic_hints,omitempty"`
+	Tags             []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Difficulty       int       `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	CreatedAt        time.Time `json:"created_at" yaml:"-"`
+	UpdatedAt        time.Time `json:"updated_at" yaml:"-"`
+}
+
+// Init 打开课程和条目表
+func Init(db *store.DB) (err error) {
+	if lessons, err = store.NewTable[Lesson](db, "lessons"); err != nil {
+		return err
+	}
+	items, err = store.NewTable[Item](db, "items")
+	return err
+}
+
+// ListLessons 返回租户的全部课程，按创建时间排序
+func ListLessons(tenant string) []*Lesson {
+	list := lessons.List(func(l *Lesson) bool {
+		return l.Tenant == tenant
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// GetLesson 查询课程
+func GetLesson(id string) (*Lesson, error) {
+	l, err := lessons.Get(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("lesson %q", id))
+	}
+	return l, err
+}
+
+// TenantLesson 查询租户的课程，其他租户的课程视为不存在
+func TenantLesson(tenant, id string) (*Lesson, error) {
+	l, err := GetLesson(id)
+	if err != nil {
+		return nil, err
+	}
+	if l.Tenant != tenant {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("lesson %q", id))
+	}
+	return l, nil
+}
+
+// SaveLesson 创建或更新课程，ID 为空时创建，更新时保留原租户和作者
+func SaveLesson(l *Lesson) error {
+	l.Title = strings.TrimSpace(l.Title)
+	if len(l.Title) == 0 {
+		return errno.ErrMissingParameter.WithFmt("title")
+	}
+
+	now := time.Now()
+	if len(l.ID) == 0 {
+		l.ID = store.NewID()
+		l.CreatedAt = now
+	} else {
+		prev, err := GetLesson(l.ID)
+		if err != nil {
+			return err
+		}
+		l.Tenant = prev.Tenant
+		l.AuthorID = prev.AuthorID
+		l.CreatedAt = prev.CreatedAt
+	}
+	l.UpdatedAt = now
+
+	if err := lessons.Put(l.ID, l); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// AuthorLesson 查询租户的课程并检查调用者是否为课程作者
+// 没有作者的课程（作者字段加入前创建）只读。
+func AuthorLesson(tenant, id, authorID string) (*Lesson, error) {
+	l, err := TenantLesson(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.AuthorID) == 0 || l.AuthorID != authorID {
+		return nil, *errno.ErrPermissionDenied
+	}
+	return l, nil
+}
+
+// DeleteLesson 删除课程及其全部条目
+func DeleteLesson(id string) error {
+	if _, err := GetLesson(id); err != nil {
+		return err
+	}
+
+	for _, it := range ListItems(id) {
+		if err := items.Delete(it.ID); err != nil {
+			return errno.ErrDatabase.WithRawErr(err)
+		}
+	}
+	if err := lessons.Delete(id); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// ListItems 返回课程下的条目，按位置排序
+func ListItems(lessonID string) []*Item {
+	list := items.List(func(it *Item) bool {
+		return it.LessonID == lessonID
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Position < list[j].Position
+	})
+	return list
+}
+
+// GetItem 查询条目
+func GetItem(id string) (*Item, error) {
+	it, err := items.Get(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("item %q", id))
+	}
+	return it, err
+}
+
+// TenantItem 查询租户课程中的条目，其他租户的条目视为不存在
+func TenantItem(tenant, id string) (*Item, error) {
+	it, err := GetItem(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = TenantLesson(tenant, it.LessonID); err != nil {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("item %q", id))
+	}
+	return it, nil
+}
+
+// SaveItem 校验并保存条目，ID 为空时追加到课程末尾
+func SaveItem(it *Item) error {
+	if _, err := GetLesson(it.LessonID); err != nil {
+		return err
+	}
+	if err := it.Validate(); err != nil {
+		return err
+	}
+	return saveItem(it, len(ListItems(it.LessonID)))
+}
+
+// DeleteItem 删除条目
+func DeleteItem(id string) error {
+	if _, err := GetItem(id); err != nil {
+		return err
+	}
+	if err := items.Delete(id); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// ImportItems 批量导入条目到课程，全部校验通过后一次写入，任一条目失败时全部不导入
+// 条目 ID 已属于该课程时更新原条目，否则作为新条目追加。
+func ImportItems(lessonID string, list []*Item) error {
+	if _, err := GetLesson(lessonID); err != nil {
+		return err
+	}
+
+	for i, it := range list {
+		if err := it.Validate(); err != nil {
+			return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf("item %d: %s", i+1, err.Error()))
+		}
+	}
+
+	position := len(ListItems(lessonID))
+	rows := map[string]*Item{}
+	for _, it := range list {
+		it.LessonID = lessonID
+		if prev, err := items.Get(it.ID); err != nil || prev.LessonID != lessonID || rows[it.ID] != nil {
+			it.ID = ""
+		}
+		if prepareItem(it, position) {
+			position++
+		}
+		rows[it.ID] = it
+	}
+
+	if err := items.PutAll(rows); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// Validate 校验条目，未设置的评测模式和引擎类型根据参考文本检测
+func (it *Item) Validate() error {
+	it.RefText = strings.TrimSpace(it.RefText)
+	if len(it.RefText) == 0 {
+		return errno.ErrMissingParameter.WithFmt("ref_text")
+	}
+
+	if it.EvalMode == nil {
+		evalMode := speech.DetectEvalMode(it.RefText)
+		it.EvalMode = &evalMode
+	}
+	if len(it.ServerEngineType) == 0 {
+		it.ServerEngineType = speech.DetectMode(it.RefText).EngineType()
+	}
+
+	if it.Difficulty < MinDifficulty || it.Difficulty > MaxDifficulty {
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"difficulty must be between %d and %d.", MinDifficulty, MaxDifficulty))
+	}
+
+	req := it.AssessmentRequest()
+	req.FillDefault()
+	req.ScoreCoeff = speech.MinScoreCoeff
+	return req.Validator()
+}
+
+// AssessmentRequest 由条目生成评测参数
+func (it *Item) AssessmentRequest() speech.AssessmentRequest {
+	req := speech.AssessmentRequest{
+		ItemID:           it.ID,
+		RefText:          it.RefText,
+		ServerEngineType: it.ServerEngineType,
+		EvalMode:         speech.EvalModeAuto,
+	}
+	if it.EvalMode != nil {
+		req.EvalMode = *it.EvalMode
+	}
+	return req
+}
+
+// saveItem 写入条目，新条目放在 position 位置
+func saveItem(it *Item, position int) error {
+	prepareItem(it, position)
+	if err := items.Put(it.ID, it); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// prepareItem 已有条目保留创建时间和位置，新条目分配 ID 并放在 position 位置，返回是否为新条目
+func prepareItem(it *Item, position int) bool {
+	now := time.Now()
+	it.UpdatedAt = now
+	if prev, err := items.Get(it.ID); err == nil {
+		it.CreatedAt = prev.CreatedAt
+		it.Position = prev.Position
+		it.LessonID = prev.LessonID
+		return false
+	}
+
+	it.ID = store.NewID()
+	it.CreatedAt = now
+	it.Position = position
+	return true
+}
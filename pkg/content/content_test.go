@@ -0,0 +1,124 @@
+package content
+
+import (
+	"testing"
+
+	"lingolift/errno"
+	"lingolift/pkg/store"
+)
+
+func TestMain(m *testing.M) {
+	db, err := store.Open(nil)
+	if err != nil {
+		panic(err)
+	}
+	if err = Init(db); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestAuthorLesson(t *testing.T) {
+	lesson := &Lesson{Title: "lesson", Tenant: "school_a", AuthorID: "author"}
+	if err := SaveLesson(lesson); err != nil {
+		t.Fatal(err)
+	}
+	legacy := &Lesson{Title: "legacy", Tenant: "school_a"}
+	if err := SaveLesson(legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	// 更新时保留原租户和作者
+	update := &Lesson{ID: lesson.ID, Title: "renamed", Tenant: "school_b", AuthorID: "other"}
+	if err := SaveLesson(update); err != nil || update.AuthorID != "author" || update.Tenant != "school_a" {
+		t.Errorf("SaveLesson() = %q/%q, %v, want school_a/author", update.Tenant, update.AuthorID, err)
+	}
+
+	tests := []struct {
+		tenant string
+		id     string
+		author string
+		code   string
+	}{
+		{"school_a", lesson.ID, "author", ""},
+		{"school_a", lesson.ID, "other", errno.ErrPermissionDenied.Code},
+		{"school_b", lesson.ID, "author", errno.ErrNotFoundResource.Code},
+		{"school_a", legacy.ID, "", errno.ErrPermissionDenied.Code},
+		{"school_a", "missing", "author", errno.ErrNotFoundResource.Code},
+	}
+
+	for _, tt := range tests {
+		_, err := AuthorLesson(tt.tenant, tt.id, tt.author)
+		if len(tt.code) == 0 {
+			if err != nil {
+				t.Errorf("AuthorLesson(%q, %q, %q) = %v, want nil", tt.tenant, tt.id, tt.author, err)
+			}
+			continue
+		}
+		if e, ok := err.(errno.Err); !ok || e.Code != tt.code {
+			t.Errorf("AuthorLesson(%q, %q, %q) = %v, want %s", tt.tenant, tt.id, tt.author, err, tt.code)
+		}
+	}
+
+	if got := len(ListLessons("school_b")); got != 0 {
+		t.Errorf("len(ListLessons(school_b)) = %d, want 0", got)
+	}
+	item := &Item{LessonID: lesson.ID, RefText: "hello"}
+	if err := SaveItem(item); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TenantItem("school_b", item.ID); err == nil {
+		t.Error("TenantItem() from another tenant succeeded")
+	}
+	if _, err := TenantItem("school_a", item.ID); err != nil {
+		t.Errorf("TenantItem() = %v", err)
+	}
+}
+
+func TestImportItems(t *testing.T) {
+	lesson := &Lesson{Title: "import", AuthorID: "author"}
+	if err := SaveLesson(lesson); err != nil {
+		t.Fatal(err)
+	}
+	existing := &Item{LessonID: lesson.ID, RefText: "hello"}
+	if err := SaveItem(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	// 任一条目校验失败时不写入任何条目
+	err := ImportItems(lesson.ID, []*Item{
+		{ID: existing.ID, RefText: "changed"},
+		{RefText: "world"},
+		{RefText: "bad", Difficulty: MaxDifficulty + 1},
+	})
+	if err == nil {
+		t.Fatal("ImportItems() with an invalid item succeeded")
+	}
+	if got := ListItems(lesson.ID); len(got) != 1 || got[0].RefText != "hello" {
+		t.Errorf("ListItems() after failed import = %+v, want only the existing item", got)
+	}
+
+	// 已有条目原位更新，重复的 ID 和其他课程的 ID 作为新条目追加
+	err = ImportItems(lesson.ID, []*Item{
+		{ID: existing.ID, RefText: "changed"},
+		{ID: existing.ID, RefText: "duplicate"},
+		{ID: "other", RefText: "world"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"changed", "duplicate", "world"}
+	got := ListItems(lesson.ID)
+	if len(got) != len(want) {
+		t.Fatalf("len(ListItems()) = %d, want %d", len(got), len(want))
+	}
+	for i, it := range got {
+		if it.RefText != want[i] || it.Position != i {
+			t.Errorf("item %d = %q at %d, want %q at %d", i, it.RefText, it.Position, want[i], i)
+		}
+	}
+	if got[0].ID != existing.ID {
+		t.Errorf("updated item ID = %q, want %q", got[0].ID, existing.ID)
+	}
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Masked 脱敏后的占位符
+const Masked = "******"
+
+// secretPatterns 字段名（忽略大小写、下划线和连字符）包含这些词时视为密钥
+var secretPatterns = []string{
+	"secret", "password", "passwd", "token", "signingkey", "apikey", "privatekey", "credential",
+}
+
+// IsSecret 字段名是否为密钥
+func IsSecret(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, p := range secretPatterns {
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask 脱敏：空值原样返回以便看出是否已配置，较长的值保留末 4 位便于核对
+func Mask(value string) string {
+	if len(value) == 0 {
+		return ""
+	}
+	if len(value) < 16 {
+		return Masked
+	}
+	return Masked + value[len(value)-4:]
+}
+
+// redactCore 写入前屏蔽字段名为密钥的字段
+// 只检查顶层字段，嵌套对象需自行实现脱敏的 MarshalLogObject。
+type redactCore struct {
+	zapcore.Core
+}
+
+// NewRedactCore
+func NewRedactCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		if !IsSecret(f.Key) {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+
+		switch f.Type {
+		case zapcore.StringType:
+			redacted[i] = zap.String(f.Key, Mask(f.String))
+		case zapcore.ByteStringType, zapcore.BinaryType:
+			redacted[i] = zap.String(f.Key, Mask(string(f.Interface.([]byte))))
+		default:
+			redacted[i] = zap.String(f.Key, Masked)
+		}
+	}
+
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
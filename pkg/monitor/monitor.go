@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"lingolift/pkg/pubsub"
+	"lingolift/pkg/speech"
+)
+
+// 每个订阅者的缓冲大小，监控端处理不过来时丢弃事件
+const subscriberBuffer = 64
+
+var (
+	broker = pubsub.NewBroker[*Event]()
+
+	// active 进行中的会话及其最近一次事件，按班级分组，新订阅者连接时先收到这些事件
+	mu     sync.Mutex
+	active = map[string]map[string]*Event{}
+)
+
+// Event 学生评测会话中的一条响应
+type Event struct {
+	ClassID      string                     `json:"class_id"`
+	SessionID    string                     `json:"session_id"`
+	UserID       string                     `json:"user_id"`
+	ItemID       string                     `json:"item_id,omitempty"`
+	AssignmentID string                     `json:"assignment_id,omitempty"`
+	RefText      string                     `json:"ref_text"`
+	Time         time.Time                  `json:"time"`
+	Response     *speech.AssessmentResponse `json:"response"`
+}
+
+// Publish 把会话的响应发布到学生所在的各个班级
+func Publish(classIDs []string, s *speech.Session, response speech.AssessmentResponse) {
+	if len(classIDs) == 0 {
+		return
+	}
+
+	finished := response.Status == "complete" || response.Status == "error"
+	for _, classID := range classIDs {
+		e := &Event{
+			ClassID:      classID,
+			SessionID:    s.ID,
+			UserID:       s.Request.UserID,
+			ItemID:       s.Request.ItemID,
+			AssignmentID: s.Request.AssignmentID,
+			RefText:      s.Request.RefText,
+			Time:         time.Now(),
+			Response:     &response,
+		}
+
+		if !finished {
+			mu.Lock()
+			if _, ok := active[classID]; !ok {
+				active[classID] = map[string]*Event{}
+			}
+			active[classID][s.ID] = e
+			mu.Unlock()
+		}
+
+		broker.Publish(classID, e)
+	}
+
+	if finished {
+		End(classIDs, s.ID)
+	}
+}
+
+// End 会话结束，从进行中的会话里移除
+// 客户端断开等没有最终结果的情况也需要调用。
+func End(classIDs []string, sessionID string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, classID := range classIDs {
+		delete(active[classID], sessionID)
+		if len(active[classID]) == 0 {
+			delete(active, classID)
+		}
+	}
+}
+
+// Subscribe 订阅班级的实时事件，返回订阅和班级中进行中会话的最近事件
+func Subscribe(classID string) (*pubsub.Subscription[*Event], []*Event) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var snapshot []*Event
+	for _, e := range active[classID] {
+		snapshot = append(snapshot, e)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Time.Before(snapshot[j].Time)
+	})
+
+	return broker.Subscribe(classID, subscriberBuffer), snapshot
+}
+
+// Unsubscribe 取消订阅
+func Unsubscribe(sub *pubsub.Subscription[*Event]) {
+	broker.Unsubscribe(sub)
+}
@@ -0,0 +1,78 @@
+package pubsub
+
+import "sync"
+
+// Subscription 订阅，从 C 中读取消息
+type Subscription[T any] struct {
+	C     <-chan T
+	topic string
+	ch    chan T
+}
+
+// Broker 按主题分发消息
+// 发布不会阻塞：订阅者处理不过来时丢弃该订阅者的消息。
+type Broker[T any] struct {
+	mu   sync.RWMutex
+	subs map[string]map[*Subscription[T]]struct{}
+}
+
+// NewBroker
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{subs: map[string]map[*Subscription[T]]struct{}{}}
+}
+
+// Subscribe 订阅主题，buffer 为订阅者的缓冲大小
+func (b *Broker[T]) Subscribe(topic string, buffer int) *Subscription[T] {
+	ch := make(chan T, buffer)
+	sub := &Subscription[T]{C: ch, topic: topic, ch: ch}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[topic]; !ok {
+		b.subs[topic] = map[*Subscription[T]]struct{}{}
+	}
+	b.subs[topic][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe 取消订阅并关闭订阅者的通道
+func (b *Broker[T]) Unsubscribe(sub *Subscription[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs, ok := b.subs[sub.topic]
+	if !ok {
+		return
+	}
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.topic)
+	}
+	close(sub.ch)
+}
+
+// Publish 向主题的所有订阅者发布消息
+func (b *Broker[T]) Publish(topic string, msg T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs[topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+		}
+	}
+}
+
+// Subscribers 主题当前的订阅者数量
+func (b *Broker[T]) Subscribers(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[topic])
+}
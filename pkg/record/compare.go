@@ -0,0 +1,186 @@
+package record
+
+import (
+	"math"
+	"strings"
+
+	"lingolift/errno"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+// 单词准确度不低于该值且没有漏读、错读时视为读对
+const correctWordAccuracy = 60
+
+// 单词对比状态
+const (
+	WordFixed     = "fixed"     // a 读错、b 读对
+	WordBroken    = "broken"    // a 读对、b 读错
+	WordImproved  = "improved"  // 得分提高
+	WordDeclined  = "declined"  // 得分下降
+	WordUnchanged = "unchanged" // 得分不变
+	WordUnmatched = "unmatched" // 只出现在其中一次结果中
+)
+
+// MetricDelta 单项指标的变化
+type MetricDelta struct {
+	A     float64 `json:"a"`
+	B     float64 `json:"b"`
+	Delta float64 `json:"delta"`
+}
+
+// WordDelta 单个单词的得分变化
+type WordDelta struct {
+	Index  int          `json:"index"`
+	Word   string       `json:"word"`
+	A      *soe.WordRsp `json:"a,omitempty"`
+	B      *soe.WordRsp `json:"b,omitempty"`
+	Delta  float64      `json:"delta"`
+	Status string       `json:"status"`
+}
+
+// Comparison 同一参考文本两次评测的对比，b 相对 a 的变化
+type Comparison struct {
+	A       string                 `json:"a"`
+	B       string                 `json:"b"`
+	RefText string                 `json:"ref_text"`
+	Metrics map[string]MetricDelta `json:"metrics"`
+	Words   []WordDelta            `json:"words"`
+	Fixed   []string               `json:"fixed"`
+	Broken  []string               `json:"broken"`
+}
+
+// Compare 对齐两次评测的单词结果，给出逐词得分变化和整体指标变化
+func Compare(a, b *Record) (*Comparison, error) {
+	if !sameText(a.RefText, b.RefText) {
+		return nil, errno.ErrInvalidParameterValue.WithFmt("sessions a and b must assess the same ref_text.")
+	}
+	if a.Result == nil || b.Result == nil {
+		return nil, errno.ErrInvalidParameterValue.WithFmt("sessions a and b must both have a final result.")
+	}
+
+	c := &Comparison{
+		A:       a.ID,
+		B:       b.ID,
+		RefText: a.RefText,
+		Metrics: map[string]MetricDelta{
+			"score":           delta(a.Result.Score, b.Result.Score),
+			"overall_score":   delta(a.Result.OverallScore, b.Result.OverallScore),
+			"pron_accuracy":   delta(a.Result.PronAccuracy, b.Result.PronAccuracy),
+			"pron_fluency":    delta(a.Result.PronFluency, b.Result.PronFluency),
+			"pron_completion": delta(a.Result.PronCompletion, b.Result.PronCompletion),
+		},
+		Fixed:  []string{},
+		Broken: []string{},
+	}
+
+	wordsA, wordsB := referenceWords(a.Result.Words), referenceWords(b.Result.Words)
+	for i, pair := range align(wordsA, wordsB) {
+		w := WordDelta{Index: i, A: pair[0], B: pair[1]}
+		switch {
+		case w.A == nil || w.B == nil:
+			w.Status = WordUnmatched
+			if w.A != nil {
+				w.Word = w.A.ReferenceWord
+			} else {
+				w.Word = w.B.ReferenceWord
+			}
+		default:
+			w.Word = w.B.ReferenceWord
+			w.Delta = round(w.B.PronAccuracy - w.A.PronAccuracy)
+			okA, okB := correct(w.A), correct(w.B)
+			switch {
+			case !okA && okB:
+				w.Status = WordFixed
+				c.Fixed = append(c.Fixed, w.Word)
+			case okA && !okB:
+				w.Status = WordBroken
+				c.Broken = append(c.Broken, w.Word)
+			case w.Delta > 0:
+				w.Status = WordImproved
+			case w.Delta < 0:
+				w.Status = WordDeclined
+			default:
+				w.Status = WordUnchanged
+			}
+		}
+		c.Words = append(c.Words, w)
+	}
+
+	return c, nil
+}
+
+// referenceWords 去掉多读的单词，只保留参考文本中的单词
+func referenceWords(words []soe.WordRsp) []*soe.WordRsp {
+	var refs []*soe.WordRsp
+	for i := range words {
+		if words[i].Tag != 1 { // 1: 多读
+			refs = append(refs, &words[i])
+		}
+	}
+	return refs
+}
+
+// align 按参考单词求最长公共子序列对齐两组结果，未对齐的单词单独成对
+func align(a, b []*soe.WordRsp) [][2]*soe.WordRsp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if sameWord(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]*soe.WordRsp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case sameWord(a[i], b[j]):
+			pairs = append(pairs, [2]*soe.WordRsp{a[i], b[j]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			pairs = append(pairs, [2]*soe.WordRsp{a[i], nil})
+			i++
+		default:
+			pairs = append(pairs, [2]*soe.WordRsp{nil, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		pairs = append(pairs, [2]*soe.WordRsp{a[i], nil})
+	}
+	for ; j < m; j++ {
+		pairs = append(pairs, [2]*soe.WordRsp{nil, b[j]})
+	}
+	return pairs
+}
+
+func sameWord(a, b *soe.WordRsp) bool {
+	return strings.EqualFold(a.ReferenceWord, b.ReferenceWord)
+}
+
+// sameText 忽略大小写和空白差异比较参考文本
+func sameText(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// correct 单词是否读对：没有漏读、错读且准确度达标
+func correct(w *soe.WordRsp) bool {
+	return w.Tag == 0 && w.PronAccuracy >= correctWordAccuracy
+}
+
+func delta(a, b float64) MetricDelta {
+	return MetricDelta{A: a, B: b, Delta: round(b - a)}
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
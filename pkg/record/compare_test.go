@@ -0,0 +1,134 @@
+package record
+
+import (
+	"reflect"
+	"testing"
+
+	"lingolift/errno"
+	"lingolift/pkg/speech"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+func TestCompare(t *testing.T) {
+	a := &Record{ID: "a", RefText: "The cat sat on the mat here", Result: &speech.SOEResult{
+		Score: 60, PronAccuracy: 70.111,
+		Words: []soe.WordRsp{
+			{ReferenceWord: "The", PronAccuracy: 90},
+			{ReferenceWord: "cat", PronAccuracy: 40},
+			{ReferenceWord: "sat", PronAccuracy: 80},
+			{ReferenceWord: "on", PronAccuracy: 80},
+			{ReferenceWord: "the", PronAccuracy: 70},
+			{ReferenceWord: "mat", PronAccuracy: 70},
+			{ReferenceWord: "here", PronAccuracy: 80},
+		},
+	}}
+	b := &Record{ID: "b", RefText: "the cat  sat on the mat here ", Result: &speech.SOEResult{
+		Score: 65, PronAccuracy: 72.5,
+		Words: []soe.WordRsp{
+			{ReferenceWord: "the", PronAccuracy: 95},
+			{ReferenceWord: "cat", PronAccuracy: 85},
+			{Word: "um", Tag: 1}, // 多读
+			{ReferenceWord: "sat", PronAccuracy: 90, Tag: 3},
+			{ReferenceWord: "the", PronAccuracy: 70},
+			{ReferenceWord: "mat", PronAccuracy: 70},
+			{ReferenceWord: "here", PronAccuracy: 70},
+		},
+	}}
+
+	c, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare() = %v", err)
+	}
+
+	words := []struct {
+		word   string
+		delta  float64
+		status string
+	}{
+		{"the", 5, WordImproved},
+		{"cat", 45, WordFixed},
+		{"sat", 10, WordBroken},
+		{"on", 0, WordUnmatched},
+		{"the", 0, WordUnchanged},
+		{"mat", 0, WordUnchanged},
+		{"here", -10, WordDeclined},
+	}
+	if len(c.Words) != len(words) {
+		t.Fatalf("len(Words) = %d, want %d", len(c.Words), len(words))
+	}
+	for i, w := range words {
+		got := c.Words[i]
+		if got.Index != i || got.Word != w.word || got.Delta != w.delta || got.Status != w.status {
+			t.Errorf("Words[%d] = %d %s %v %s, want %d %s %v %s", i,
+				got.Index, got.Word, got.Delta, got.Status, i, w.word, w.delta, w.status)
+		}
+	}
+
+	if !reflect.DeepEqual(c.Fixed, []string{"cat"}) || !reflect.DeepEqual(c.Broken, []string{"sat"}) {
+		t.Errorf("Fixed, Broken = %q, %q, want [cat], [sat]", c.Fixed, c.Broken)
+	}
+	if m := c.Metrics["score"]; m.A != 60 || m.B != 65 || m.Delta != 5 {
+		t.Errorf("Metrics[score] = %+v, want 60 -> 65", m)
+	}
+	if m := c.Metrics["pron_accuracy"]; m.Delta != 2.39 {
+		t.Errorf("Metrics[pron_accuracy].Delta = %v, want 2.39", m.Delta)
+	}
+}
+
+func TestAlign(t *testing.T) {
+	words := func(list ...string) []*soe.WordRsp {
+		var out []*soe.WordRsp
+		for _, w := range list {
+			out = append(out, &soe.WordRsp{ReferenceWord: w})
+		}
+		return out
+	}
+
+	tests := []struct {
+		a, b []*soe.WordRsp
+		want []string // 每对的单词，未对齐的一侧为 "-"
+	}{
+		{words("a", "b", "c"), words("a", "b", "c"), []string{"a a", "b b", "c c"}},
+		{words("a", "b", "c"), words("a", "c"), []string{"a a", "b -", "c c"}},
+		{words("a", "c"), words("a", "b", "c"), []string{"a a", "- b", "c c"}},
+		{words("a", "b"), nil, []string{"a -", "b -"}},
+		{nil, words("a"), []string{"- a"}},
+		{words("x", "a", "b"), words("a", "b", "y"), []string{"x -", "a a", "b b", "- y"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, pair := range align(tt.a, tt.b) {
+			s := [2]string{"-", "-"}
+			for k, w := range pair {
+				if w != nil {
+					s[k] = w.ReferenceWord
+				}
+			}
+			got = append(got, s[0]+" "+s[1])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("align() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCompareInvalid(t *testing.T) {
+	result := &speech.SOEResult{}
+
+	tests := []struct {
+		name string
+		a, b *Record
+	}{
+		{"different text", &Record{RefText: "hello", Result: result}, &Record{RefText: "world", Result: result}},
+		{"missing result", &Record{RefText: "hello", Result: result}, &Record{RefText: "hello"}},
+	}
+
+	for _, tt := range tests {
+		_, err := Compare(tt.a, tt.b)
+		if e, ok := err.(errno.Err); !ok || e.Code != errno.ErrInvalidParameterValue.Code {
+			t.Errorf("%s: Compare() = %v, want %s", tt.name, err, errno.ErrInvalidParameterValue.Code)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/anticheat"
+	"lingolift/pkg/log"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/store"
+)
+
+var (
+	// records 评测记录表
+	records *store.Table[Record]
+
+	// signingKey 锁定记录的签名密钥
+	signingKey []byte
+)
+
+// Options 评测记录配置
+type Options struct {
+	// SigningKey 考试记录的 HMAC 签名密钥，为空时不能使用考试模式
+	SigningKey string `yaml:"signing_key"`
+}
+
+// String 密钥脱敏后输出
+func (o Options) String() string {
+	return fmt.Sprintf("{SigningKey:%s}", log.Mask(o.SigningKey))
+}
+
+// Record 一次评测会话的最终结果
+type Record struct {
+	ID               string            `json:"id"`
+	Tenant           string            `json:"tenant,omitempty"`
+	Region           string            `json:"region,omitempty"`
+	UserID           string            `json:"user_id,omitempty"`
+	ItemID           string            `json:"item_id,omitempty"`
+	AssignmentID     string            `json:"assignment_id,omitempty"`
+	RefText          string            `json:"ref_text"`
+	ServerEngineType string            `json:"server_engine_type"`
+	EvalMode         int64             `json:"eval_mode"`
+	ScoringProfile   string            `json:"scoring_profile,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	Result           *speech.SOEResult `json:"result"`
+
+	// AntiCheat 防作弊检测结果
+	AntiCheat *anticheat.Report `json:"anti_cheat,omitempty"`
+
+	// Locked 考试记录保存后不可修改，Signature 为记录内容的签名
+	Locked    bool   `json:"locked,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// Init 打开评测记录表
+func Init(db *store.DB, opts *Options) (err error) {
+	if opts != nil && len(opts.SigningKey) > 0 {
+		signingKey = []byte(opts.SigningKey)
+	}
+
+	records, err = store.NewTable[Record](db, "sessions")
+	return err
+}
+
+// Save 保存评测记录，未指定 ID 时自动生成
+func Save(r *Record) error {
+	if len(r.ID) == 0 {
+		r.ID = store.NewID()
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+
+	if prev, err := records.Get(r.ID); err == nil && prev.Locked {
+		return errno.ErrOperateFailed.WithFmt(fmt.Sprintf("session %q is locked.", r.ID))
+	}
+
+	if r.Locked {
+		signature, err := sign(r)
+		if err != nil {
+			return err
+		}
+		r.Signature = signature
+	}
+
+	if err := records.Put(r.ID, r); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// Get 查询评测记录
+func Get(id string) (*Record, error) {
+	r, err := records.Get(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errno.ErrNotFoundResource.WithFmt(fmt.Sprintf("session %q", id))
+	}
+	return r, err
+}
+
+// FromSession 根据会话的请求参数和最终结果生成评测记录
+func FromSession(s *speech.Session, result *speech.SOEResult) *Record {
+	r := &Record{
+		ID:               s.ID,
+		Tenant:           s.Request.Tenant,
+		Region:           s.Request.Region,
+		UserID:           s.Request.UserID,
+		ItemID:           s.Request.ItemID,
+		AssignmentID:     s.Request.AssignmentID,
+		RefText:          s.Request.RefText,
+		ServerEngineType: s.Request.ServerEngineType,
+		EvalMode:         s.Request.EvalMode,
+		Result:           result,
+		Locked:           s.Exam,
+	}
+	if s.Scorer != nil {
+		r.ScoringProfile = s.Scorer.Name
+	}
+	return r
+}
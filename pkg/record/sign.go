@@ -0,0 +1,46 @@
+package record
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"lingolift/errno"
+)
+
+// CanSign 是否配置了签名密钥
+func CanSign() bool {
+	return len(signingKey) > 0
+}
+
+// Verify 校验锁定记录的签名，记录内容被修改过时返回 false
+func Verify(r *Record) bool {
+	if !r.Locked || len(r.Signature) == 0 || !CanSign() {
+		return false
+	}
+
+	expected, err := sign(r)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(r.Signature))
+}
+
+// sign 对不含签名的记录 JSON 计算 HMAC-SHA256
+func sign(r *Record) (string, error) {
+	if !CanSign() {
+		return "", errno.ErrOperateFailed.WithFmt("record signing key is not configured.")
+	}
+
+	unsigned := *r
+	unsigned.Signature = ""
+	content, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,101 @@
+package record
+
+import (
+	"testing"
+
+	"lingolift/errno"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/store"
+)
+
+func TestMain(m *testing.M) {
+	db, err := store.Open(nil)
+	if err != nil {
+		panic(err)
+	}
+	if err = Init(db, &Options{SigningKey: "secret"}); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestVerify(t *testing.T) {
+	r := &Record{ID: "exam", UserID: "u1", RefText: "hello", Locked: true, Result: &speech.SOEResult{Score: 80}}
+	if err := Save(r); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	if len(r.Signature) == 0 {
+		t.Fatal("Save() did not sign the locked record")
+	}
+
+	saved, err := Get(r.ID)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *Record)
+		want   bool
+	}{
+		{"unchanged", func(r *Record) {}, true},
+		{"score changed", func(r *Record) { r.Result.Score = 100 }, false},
+		{"user changed", func(r *Record) { r.UserID = "u2" }, false},
+		{"signature changed", func(r *Record) { r.Signature = r.Signature[1:] + "0" }, false},
+		{"signature removed", func(r *Record) { r.Signature = "" }, false},
+		{"unlocked", func(r *Record) { r.Locked = false }, false},
+	}
+
+	for _, tt := range tests {
+		r, err := Get(saved.ID)
+		if err != nil {
+			t.Fatalf("Get() = %v", err)
+		}
+		tt.modify(r)
+		if got := Verify(r); got != tt.want {
+			t.Errorf("%s: Verify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSaveLocked(t *testing.T) {
+	r := &Record{ID: "locked", RefText: "hello", Locked: true, Result: &speech.SOEResult{Score: 60}}
+	if err := Save(r); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	// 锁定的记录不能被覆盖
+	overwrite := &Record{ID: "locked", RefText: "hello", Result: &speech.SOEResult{Score: 100}}
+	err := Save(overwrite)
+	if e, ok := err.(errno.Err); !ok || e.Code != errno.ErrOperateFailed.Code {
+		t.Errorf("Save(locked) = %v, want %s", err, errno.ErrOperateFailed.Code)
+	}
+	if saved, _ := Get(r.ID); saved == nil || saved.Result.Score != 60 {
+		t.Errorf("Get() after overwrite = %+v, want the original record", saved)
+	}
+
+	// 未锁定的记录可以覆盖，且不签名
+	open := &Record{ID: "open", RefText: "hello", Result: &speech.SOEResult{Score: 60}}
+	for i := 0; i < 2; i++ {
+		if err := Save(open); err != nil {
+			t.Fatalf("Save(open) = %v", err)
+		}
+	}
+	if len(open.Signature) > 0 || Verify(open) {
+		t.Errorf("unlocked record signature = %q, want none", open.Signature)
+	}
+}
+
+func TestSignWithoutKey(t *testing.T) {
+	defer func(key []byte) { signingKey = key }(signingKey)
+	signingKey = nil
+
+	r := &Record{ID: "nokey", RefText: "hello", Locked: true}
+	err := Save(r)
+	if e, ok := err.(errno.Err); !ok || e.Code != errno.ErrOperateFailed.Code {
+		t.Errorf("Save() without signing key = %v, want %s", err, errno.ErrOperateFailed.Code)
+	}
+	if _, err := Get(r.ID); err == nil {
+		t.Error("Get() found a locked record saved without signing key")
+	}
+}
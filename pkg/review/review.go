@@ -0,0 +1,203 @@
+package review
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/record"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/store"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+// SM-2 参数
+const (
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+
+	// 评分低于该值视为没有掌握，需要重新开始复习
+	passQuality = 3
+
+	// 单次返回的复习条目默认数量
+	DefaultLimit = 20
+)
+
+var (
+	cards *store.Table[Card]
+
+	// 同一用户的多次评测可能同时完成，更新复习计划时串行处理
+	mu sync.Mutex
+)
+
+// Card 单词的复习计划，用户 ID 只在租户内唯一
+type Card struct {
+	ID          string  `json:"id"`
+	Tenant      string  `json:"tenant,omitempty"`
+	UserID      string  `json:"user_id"`
+	Word        string  `json:"word"`
+	Language    string  `json:"language"`
+	EaseFactor  float64 `json:"ease_factor"`
+	Interval    int     `json:"interval"` // 复习间隔（天）
+	Repetitions int     `json:"repetitions"`
+	Lapses      int     `json:"lapses"` // 没有掌握的次数
+
+	LastScore      float64   `json:"last_score"`
+	LastQuality    int       `json:"last_quality"`
+	LastReviewedAt time.Time `json:"last_reviewed_at"`
+	DueAt          time.Time `json:"due_at"`
+
+	// 最近一次出现该单词的内容条目和参考文本，便于客户端安排练习
+	ItemID  string `json:"item_id,omitempty"`
+	RefText string `json:"ref_text"`
+}
+
+// Init 打开复习计划表
+func Init(db *store.DB) (err error) {
+	cards, err = store.NewTable[Card](db, "review_cards")
+	return err
+}
+
+// Due 返回租户中的用户已到期的复习条目，按到期时间排序，同时到期时难度大的优先
+func Due(tenant, userID string, now time.Time, limit int) []*Card {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	list := cards.List(func(c *Card) bool {
+		return c.Tenant == tenant && c.UserID == userID && !c.DueAt.After(now)
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		if !list[i].DueAt.Equal(list[j].DueAt) {
+			return list[i].DueAt.Before(list[j].DueAt)
+		}
+		return list[i].EaseFactor < list[j].EaseFactor
+	})
+
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// Update 根据评测记录更新用户的复习计划
+// 得分低的单词加入复习队列，已在队列中的单词按本次得分重新安排。
+func Update(userID string, r *record.Record) error {
+	if len(userID) == 0 || r.Result == nil {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	language, _ := speech.EngineLanguage(r.ServerEngineType)
+
+	// 一次评测的全部单词一次写入
+	now := r.CreatedAt
+	updated := map[string]*Card{}
+	for word, w := range worstWords(r.Result.Words) {
+		id := cardID(r.Tenant, userID, language, word)
+		q := quality(w)
+
+		card, err := cards.Get(id)
+		if err != nil {
+			// 只有没掌握的单词才加入复习队列
+			if q >= passQuality {
+				continue
+			}
+			card = &Card{
+				ID:         id,
+				Tenant:     r.Tenant,
+				UserID:     userID,
+				Word:       w.ReferenceWord,
+				Language:   language,
+				EaseFactor: defaultEaseFactor,
+			}
+		}
+
+		card.ItemID = r.ItemID
+		card.RefText = r.RefText
+		card.LastScore = w.PronAccuracy
+		schedule(card, q, now)
+		updated[card.ID] = card
+	}
+
+	if len(updated) == 0 {
+		return nil
+	}
+	if err := cards.PutAll(updated); err != nil {
+		return errno.ErrDatabase.WithRawErr(err)
+	}
+	return nil
+}
+
+// schedule SM-2 算法：根据本次评分 q（0-5）计算下一次复习时间
+func schedule(c *Card, q int, now time.Time) {
+	if q < passQuality {
+		c.Repetitions = 0
+		c.Interval = 1
+		c.Lapses++
+	} else {
+		c.Repetitions++
+		switch c.Repetitions {
+		case 1:
+			c.Interval = 1
+		case 2:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.EaseFactor))
+		}
+	}
+
+	d := float64(5 - q)
+	c.EaseFactor = math.Max(minEaseFactor, c.EaseFactor+0.1-d*(0.08+d*0.02))
+
+	c.LastQuality = q
+	c.LastReviewedAt = now
+	c.DueAt = now.AddDate(0, 0, c.Interval)
+}
+
+// quality 把单词准确度（0-100）换算为 SM-2 评分，漏读、错读最多 1 分
+func quality(w soe.WordRsp) int {
+	q := int(math.Round(w.PronAccuracy / 20))
+	q = max(0, min(5, q))
+	if w.Tag != 0 {
+		q = min(q, 1)
+	}
+	return q
+}
+
+// worstWords 同一单词在一次评测中出现多次时取得分最低的一次，忽略多读的单词
+func worstWords(words []soe.WordRsp) map[string]soe.WordRsp {
+	worst := map[string]soe.WordRsp{}
+	for _, w := range words {
+		if w.Tag == 1 { // 1: 多读
+			continue
+		}
+		key := normalizeWord(w.ReferenceWord)
+		if len(key) == 0 {
+			continue
+		}
+		if prev, ok := worst[key]; !ok || quality(w) < quality(prev) {
+			worst[key] = w
+		}
+	}
+	return worst
+}
+
+// cardID 未配置租户时不带租户前缀，与租户加入前的 ID 一致
+func cardID(tenant, userID, language, word string) string {
+	id := userID + ":" + language + ":" + word
+	if len(tenant) > 0 {
+		id = tenant + ":" + id
+	}
+	return id
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(word), `.,!?;:"'()[]“”‘’，。！？；：`))
+}
@@ -0,0 +1,140 @@
+package review
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"lingolift/pkg/record"
+	"lingolift/pkg/speech"
+	"lingolift/pkg/store"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+func TestMain(m *testing.M) {
+	db, err := store.Open(nil)
+	if err != nil {
+		panic(err)
+	}
+	if err = Init(db); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	card := &Card{EaseFactor: defaultEaseFactor}
+
+	// 连续复习同一张卡片，每步的结果依赖上一步
+	steps := []struct {
+		quality     int
+		interval    int
+		repetitions int
+		lapses      int
+		easeFactor  float64
+	}{
+		{5, 1, 1, 0, 2.6},
+		{5, 6, 2, 0, 2.7},
+		{4, 16, 3, 0, 2.7},
+		{2, 1, 0, 1, 2.38},
+		{3, 1, 1, 1, 2.24},
+		{4, 6, 2, 1, 2.24},
+		{0, 1, 0, 2, 1.44},
+		{0, 1, 0, 3, minEaseFactor},
+	}
+
+	for i, s := range steps {
+		schedule(card, s.quality, now)
+		if card.Interval != s.interval || card.Repetitions != s.repetitions || card.Lapses != s.lapses ||
+			math.Abs(card.EaseFactor-s.easeFactor) > 1e-9 {
+			t.Fatalf("step %d: schedule(q=%d) = interval %d, repetitions %d, lapses %d, ease %v; want %d, %d, %d, %v",
+				i, s.quality, card.Interval, card.Repetitions, card.Lapses, card.EaseFactor,
+				s.interval, s.repetitions, s.lapses, s.easeFactor)
+		}
+		if want := now.AddDate(0, 0, s.interval); !card.DueAt.Equal(want) {
+			t.Errorf("step %d: DueAt = %v, want %v", i, card.DueAt, want)
+		}
+		if card.LastQuality != s.quality || !card.LastReviewedAt.Equal(now) {
+			t.Errorf("step %d: LastQuality, LastReviewedAt = %d, %v", i, card.LastQuality, card.LastReviewedAt)
+		}
+	}
+}
+
+func TestQuality(t *testing.T) {
+	tests := []struct {
+		accuracy float64
+		tag      int64
+		want     int
+	}{
+		{100, 0, 5},
+		{89, 0, 4},
+		{50, 0, 3},
+		{49, 0, 2},
+		{0, 0, 0},
+		{-1, 0, 0},
+		{95, 3, 1}, // 错读
+		{95, 2, 1}, // 漏读
+		{5, 2, 0},
+	}
+
+	for _, tt := range tests {
+		if got := quality(soe.WordRsp{PronAccuracy: tt.accuracy, Tag: tt.tag}); got != tt.want {
+			t.Errorf("quality(%v, tag %d) = %d, want %d", tt.accuracy, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	r := &record.Record{
+		Tenant: "t1", ItemID: "item", RefText: "Hello world, world!", ServerEngineType: "16k_en", CreatedAt: now,
+		Result: &speech.SOEResult{Words: []soe.WordRsp{
+			{ReferenceWord: "Hello", PronAccuracy: 90},
+			{ReferenceWord: "world,", PronAccuracy: 80},
+			{Word: "um", Tag: 1},
+			{ReferenceWord: "world!", PronAccuracy: 30},
+		}},
+	}
+	if err := Update("u1", r); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tenant string
+		user   string
+		now    time.Time
+		want   []string
+	}{
+		{"not due yet", "t1", "u1", now, nil},
+		{"due", "t1", "u1", now.AddDate(0, 0, 1), []string{"world!"}},
+		{"other tenant", "t2", "u1", now.AddDate(0, 0, 1), nil},
+		{"other user", "t1", "u2", now.AddDate(0, 0, 1), nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, c := range Due(tt.tenant, tt.user, tt.now, 0) {
+			got = append(got, c.Word)
+		}
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("%s: Due() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 已在队列中的单词读对后按 SM-2 重新安排
+	r.CreatedAt = now.AddDate(0, 0, 1)
+	r.Result.Words = []soe.WordRsp{{ReferenceWord: "world", PronAccuracy: 100}}
+	if err := Update("u1", r); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	card, err := cards.Get(cardID("t1", "u1", "en", "world"))
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if card.Repetitions != 1 || card.Lapses != 1 || card.LastScore != 100 || !card.DueAt.Equal(now.AddDate(0, 0, 2)) {
+		t.Errorf("card = %+v, want one repetition after one lapse, due in a day", card)
+	}
+}
@@ -0,0 +1,145 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeystoreOptions 加密的本地密钥库
+// 密钥库使用 AES-256-GCM 加密，主密钥为 base64 编码的 32 字节随机数，应与密钥库分开存放。
+type KeystoreOptions struct {
+	File    string `yaml:"file"`
+	KeyFile string `yaml:"key_file"`
+}
+
+// keystoreFile 密钥库文件格式
+type keystoreFile struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// keystoreProvider 每次读取都重新解密文件，更新密钥库后无需重启
+type keystoreProvider struct {
+	opts *KeystoreOptions
+}
+
+func (p *keystoreProvider) Get(name string) (string, error) {
+	key, err := ReadKey(p.opts.KeyFile)
+	if err != nil {
+		return "", err
+	}
+
+	secrets, err := LoadKeystore(p.opts.File, key)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found in keystore", name)
+	}
+	return value, nil
+}
+
+// GenerateKey 生成 base64 编码的主密钥
+func GenerateKey() string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ReadKey 读取主密钥文件
+func ReadKey(filename string) ([]byte, error) {
+	if len(filename) == 0 {
+		return nil, errors.New("keystore key_file is not configured")
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("keystore key in %s must be 32 bytes encoded in base64", filename)
+	}
+	return key, nil
+}
+
+// LoadKeystore 解密密钥库，文件不存在时返回空的密钥库
+func LoadKeystore(filename string, key []byte) (map[string]string, error) {
+	content, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file keystoreFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("parse keystore %s: %w", filename, err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore %s: wrong key or corrupted file", filename)
+	}
+
+	secrets := map[string]string{}
+	if err = json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("parse keystore %s: %w", filename, err)
+	}
+	return secrets, nil
+}
+
+// SaveKeystore 加密并保存密钥库，写入临时文件后替换
+func SaveKeystore(filename string, key []byte, secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	file := keystoreFile{Nonce: make([]byte, aead.NonceSize())}
+	if _, err = rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plain, nil)
+
+	content, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err = os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
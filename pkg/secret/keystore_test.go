@@ -0,0 +1,94 @@
+package secret
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "keystore.key")
+	file := filepath.Join(dir, "keystore.json")
+	if err := os.WriteFile(keyFile, []byte(GenerateKey()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ReadKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 文件不存在时为空的密钥库
+	secrets, err := LoadKeystore(file, key)
+	if err != nil || len(secrets) != 0 {
+		t.Fatalf("LoadKeystore() = %v, %v, want empty keystore", secrets, err)
+	}
+
+	secrets = map[string]string{"secret_id": "id", "secret_key": "key"}
+	if err = SaveKeystore(file, key, secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewResolver(&Options{Keystore: &KeystoreOptions{File: file, KeyFile: keyFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"keystore://secret_id", "id", false},
+		{"keystore://secret_key", "key", false},
+		{"keystore://missing", "", true},
+		{"plain-value", "plain-value", false},
+		{"vault://secret/data/lingolift#secret_key", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := r.Resolve(tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Resolve(%q) error = %v, want error %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+
+	// 使用其他主密钥无法解密
+	other, _ := base64.StdEncoding.DecodeString(GenerateKey())
+	if _, err = LoadKeystore(file, other); err == nil {
+		t.Error("LoadKeystore() with another key succeeded")
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", GenerateKey(), false},
+		{"not base64", "not a key", true},
+		{"short key", base64.StdEncoding.EncodeToString([]byte("short")), true},
+	}
+
+	for _, tt := range tests {
+		filename := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(filename, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadKey(filename); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ReadKey() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := ReadKey(""); err == nil {
+		t.Error("ReadKey(\"\") succeeded")
+	}
+}
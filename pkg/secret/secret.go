@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 密钥引用的格式为 <scheme>://<location>，不带以下前缀的值按明文使用
+//
+//	file:///run/secrets/secret_key            读取文件内容（去掉首尾空白）
+//	keystore://secret_key                     从加密的本地密钥库读取
+//	vault://secret/data/lingolift#secret_key  从 Vault 兼容的 KV 接口读取字段
+const (
+	SchemeFile     = "file"
+	SchemeKeystore = "keystore"
+	SchemeVault    = "vault"
+)
+
+// DefaultRefreshInterval 默认的刷新间隔（秒）
+const DefaultRefreshInterval = 300
+
+// Options 密钥来源配置
+type Options struct {
+	// RefreshInterval 重新读取密钥引用的间隔（秒），小于 0 时不刷新
+	RefreshInterval int `yaml:"refresh_interval"`
+
+	Keystore *KeystoreOptions `yaml:"keystore"`
+	Vault    *VaultOptions    `yaml:"vault"`
+}
+
+// Provider 按引用读取密钥
+type Provider interface {
+	Get(location string) (string, error)
+}
+
+// Resolver 按引用的 scheme 选择 Provider
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver 根据配置创建 Resolver，file 始终可用，keystore 和 vault 需要配置后才能使用
+func NewResolver(opts *Options) (*Resolver, error) {
+	r := &Resolver{providers: map[string]Provider{SchemeFile: fileProvider{}}}
+	if opts == nil {
+		return r, nil
+	}
+
+	if opts.Keystore != nil && len(opts.Keystore.File) > 0 {
+		r.providers[SchemeKeystore] = &keystoreProvider{opts: opts.Keystore}
+	}
+
+	if opts.Vault != nil && len(opts.Vault.Address) > 0 {
+		vault, err := newVaultProvider(opts.Vault)
+		if err != nil {
+			return nil, err
+		}
+		r.providers[SchemeVault] = vault
+	}
+
+	return r, nil
+}
+
+// IsRef 值是否为密钥引用
+func IsRef(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return false
+	}
+	switch scheme {
+	case SchemeFile, SchemeKeystore, SchemeVault:
+		return true
+	}
+	return false
+}
+
+// Resolve 解析密钥引用，明文值原样返回
+func (r *Resolver) Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+
+	scheme, location, _ := strings.Cut(value, "://")
+	provider, ok := r.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("secret provider %q is not configured", scheme)
+	}
+
+	secret, err := provider.Get(location)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s secret %s: %w", scheme, location, err)
+	}
+	return secret, nil
+}
+
+// fileProvider 读取文件内容，适用于容器编排挂载的密钥文件
+type fileProvider struct{}
+
+func (fileProvider) Get(location string) (string, error) {
+	content, err := os.ReadFile(location)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
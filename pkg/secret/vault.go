@@ -0,0 +1,110 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultOptions Vault 兼容的 KV 接口
+// 引用格式为 vault://<path>#<field>，同时支持 KV v1（data.<field>）和 v2（data.data.<field>）的响应。
+type VaultOptions struct {
+	Address string `yaml:"address"`
+
+	// Token 访问令牌，也可以是 file:// 引用；为空时使用环境变量 VAULT_TOKEN
+	Token string `yaml:"token"`
+
+	// Namespace 企业版命名空间，可为空
+	Namespace string `yaml:"namespace"`
+
+	// Timeout 请求超时（秒），默认 5
+	Timeout int `yaml:"timeout"`
+}
+
+type vaultProvider struct {
+	opts   *VaultOptions
+	client *http.Client
+}
+
+func newVaultProvider(opts *VaultOptions) (*vaultProvider, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 5
+	}
+
+	if IsRef(opts.Token) && !strings.HasPrefix(opts.Token, SchemeFile+"://") {
+		return nil, fmt.Errorf("vault token only supports file:// references")
+	}
+
+	return &vaultProvider{
+		opts:   opts,
+		client: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}, nil
+}
+
+func (p *vaultProvider) Get(location string) (string, error) {
+	path, field, ok := strings.Cut(location, "#")
+	if !ok || len(field) == 0 {
+		return "", fmt.Errorf("vault reference must be <path>#<field>")
+	}
+
+	token, err := p.token()
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(p.opts.Address, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if len(p.opts.Namespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", p.opts.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("parse vault response: %w", err)
+	}
+
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("field %q not found", field)
+	}
+	return value, nil
+}
+
+// token 每次请求重新读取，令牌文件轮换后无需重启
+func (p *vaultProvider) token() (string, error) {
+	token := p.opts.Token
+	if len(token) == 0 {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if strings.HasPrefix(token, SchemeFile+"://") {
+		return fileProvider{}.Get(strings.TrimPrefix(token, SchemeFile+"://"))
+	}
+	if len(token) == 0 {
+		return "", fmt.Errorf("vault token is not configured")
+	}
+	return token, nil
+}
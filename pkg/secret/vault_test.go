@@ -0,0 +1,79 @@
+package secret
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if got := r.Header.Get("X-Vault-Namespace"); got != "school" {
+			t.Errorf("X-Vault-Namespace = %q, want school", got)
+		}
+		switch r.URL.Path {
+		case "/v1/kv/lingolift":
+			w.Write([]byte(`{"data":{"secret_key":"v1-key"}}`))
+		case "/v1/secret/data/lingolift":
+			w.Write([]byte(`{"data":{"data":{"secret_key":"v2-key"},"metadata":{"version":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("root-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"kv v1", "root-token", "vault://kv/lingolift#secret_key", "v1-key", false},
+		{"kv v2", "root-token", "vault://secret/data/lingolift#secret_key", "v2-key", false},
+		{"token file", "file://" + tokenFile, "vault://secret/data/lingolift#secret_key", "v2-key", false},
+		{"missing field", "root-token", "vault://secret/data/lingolift#secret_id", "", true},
+		{"missing path", "root-token", "vault://secret/data/other#secret_key", "", true},
+		{"reference without field", "root-token", "vault://secret/data/lingolift", "", true},
+		{"wrong token", "other-token", "vault://kv/lingolift#secret_key", "", true},
+		{"missing token file", "file://" + tokenFile + ".missing", "vault://kv/lingolift#secret_key", "", true},
+	}
+
+	for _, tt := range tests {
+		r, err := NewResolver(&Options{Vault: &VaultOptions{
+			Address:   server.URL + "/",
+			Token:     tt.token,
+			Namespace: "school",
+		}})
+		if err != nil {
+			t.Fatalf("%s: NewResolver() = %v", tt.name, err)
+		}
+
+		got, err := r.Resolve(tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Resolve(%q) error = %v, want error %v", tt.name, tt.ref, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Resolve(%q) = %q, want %q", tt.name, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestVaultTokenReference(t *testing.T) {
+	_, err := NewResolver(&Options{Vault: &VaultOptions{Address: "http://vault", Token: "keystore://token"}})
+	if err == nil {
+		t.Error("NewResolver() with a keystore token succeeded, want only file:// references")
+	}
+}
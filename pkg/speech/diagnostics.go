@@ -0,0 +1,234 @@
+package speech
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+// 音素错误类型
+const (
+	PhoneErrSubstitution = "substitution"
+	PhoneErrOmission     = "omission"
+	PhoneErrInsertion    = "insertion"
+	PhoneErrStress       = "stress"
+)
+
+// MatchTag 取值：0 匹配，1 多读，2 缺读，3 错读
+const (
+	matchTagMatched  = 0
+	matchTagInserted = 1
+	matchTagOmitted  = 2
+	matchTagMismatch = 3
+)
+
+const (
+	// 平均准确度低于该值的音素即使没有明确错误也视为薄弱音素
+	weakPhoneAccuracy = 75
+
+	// 返回的薄弱音素数量
+	maxWeakPhones = 5
+)
+
+// PhoneResult 扁平化后的单个音素结果
+type PhoneResult struct {
+	WordIndex       int     `json:"word_index"`
+	Word            string  `json:"word"`
+	Phone           string  `json:"phone"`
+	ReferencePhone  string  `json:"reference_phone"`
+	ReferenceLetter string  `json:"reference_letter,omitempty"`
+	PronAccuracy    float64 `json:"pron_accuracy"`
+	Stress          bool    `json:"stress"`
+	DetectedStress  bool    `json:"detected_stress"`
+	BeginTime       int64   `json:"begin_time"`
+	EndTime         int64   `json:"end_time"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// PhonemeStat 单个音素的汇总统计
+type PhonemeStat struct {
+	Phone       string         `json:"phone"`
+	Count       int            `json:"count"`
+	Errors      int            `json:"errors"`
+	ErrorTypes  map[string]int `json:"error_types,omitempty"`
+	AvgAccuracy float64        `json:"avg_accuracy"`
+	Words       []string       `json:"words"`
+	Tip         string         `json:"tip"`
+}
+
+// Diagnostics 音素级发音诊断
+type Diagnostics struct {
+	Phones      []PhoneResult  `json:"phones"`
+	ErrorCounts map[string]int `json:"error_counts"`
+	Weakest     []PhonemeStat  `json:"weakest"`
+}
+
+// Diagnose 展开单词结果中的音素信息，对错误分类并给出最薄弱的音素
+func Diagnose(words []soe.WordRsp) *Diagnostics {
+	d := &Diagnostics{ErrorCounts: map[string]int{}}
+
+	stats := map[string]*PhonemeStat{}
+	for i, w := range words {
+		for _, p := range w.PhoneInfo {
+			phone := PhoneResult{
+				WordIndex:       i,
+				Word:            w.ReferenceWord,
+				Phone:           p.Phone,
+				ReferencePhone:  p.ReferencePhone,
+				ReferenceLetter: p.ReferenceLetter,
+				PronAccuracy:    p.PronAccuracy,
+				Stress:          p.Stress,
+				DetectedStress:  p.DetectedStress,
+				BeginTime:       p.Mbtm,
+				EndTime:         p.Metm,
+				Error:           classifyPhone(p),
+			}
+			d.Phones = append(d.Phones, phone)
+
+			if len(phone.Error) > 0 {
+				d.ErrorCounts[phone.Error]++
+			}
+
+			key := phone.ReferencePhone
+			if len(key) == 0 {
+				key = phone.Phone // 多读的音素没有参考音素
+			}
+			if len(key) == 0 {
+				continue
+			}
+
+			stat, ok := stats[key]
+			if !ok {
+				stat = &PhonemeStat{Phone: key, ErrorTypes: map[string]int{}}
+				stats[key] = stat
+			}
+			stat.Count++
+			stat.AvgAccuracy += phone.PronAccuracy
+			if len(phone.Error) > 0 {
+				stat.Errors++
+				stat.ErrorTypes[phone.Error]++
+				if !containsString(stat.Words, phone.Word) {
+					stat.Words = append(stat.Words, phone.Word)
+				}
+			}
+		}
+	}
+
+	for _, stat := range stats {
+		stat.AvgAccuracy /= float64(stat.Count)
+		if stat.Errors == 0 && stat.AvgAccuracy >= weakPhoneAccuracy {
+			continue
+		}
+		stat.Tip = PhoneTip(stat.Phone)
+		d.Weakest = append(d.Weakest, *stat)
+	}
+
+	// 错误率高的排在前面，错误率相同时按平均准确度升序
+	sort.Slice(d.Weakest, func(i, j int) bool {
+		ri := float64(d.Weakest[i].Errors) / float64(d.Weakest[i].Count)
+		rj := float64(d.Weakest[j].Errors) / float64(d.Weakest[j].Count)
+		if ri != rj {
+			return ri > rj
+		}
+		if d.Weakest[i].AvgAccuracy != d.Weakest[j].AvgAccuracy {
+			return d.Weakest[i].AvgAccuracy < d.Weakest[j].AvgAccuracy
+		}
+		return d.Weakest[i].Phone < d.Weakest[j].Phone
+	})
+	if len(d.Weakest) > maxWeakPhones {
+		d.Weakest = d.Weakest[:maxWeakPhones]
+	}
+
+	return d
+}
+
+// classifyPhone 音素错误分类，无错误返回空字符串
+func classifyPhone(p soe.PhoneInfoTypeRsp) string {
+	switch p.Tag {
+	case matchTagInserted:
+		return PhoneErrInsertion
+	case matchTagOmitted:
+		return PhoneErrOmission
+	case matchTagMismatch:
+		return PhoneErrSubstitution
+	}
+
+	if len(p.Phone) > 0 && len(p.ReferencePhone) > 0 && p.Phone != p.ReferencePhone {
+		return PhoneErrSubstitution
+	}
+
+	// 音素本身读对时才检查重音，应重读未重读或不应重读却重读
+	if p.Stress != p.DetectedStress {
+		return PhoneErrStress
+	}
+
+	return ""
+}
+
+// PhoneTip 返回音素的发音提示
+func PhoneTip(phone string) string {
+	if tip, ok := phoneTips[strings.ToLower(phone)]; ok {
+		return tip
+	}
+	return "Listen to a model recording of this sound and repeat it slowly, then at normal speed."
+}
+
+// 英文音素兼容 IPA 和 ARPAbet 两种写法，中文为拼音声母/韵母
+var phoneTips = map[string]string{
+	"θ":  "Put the tip of your tongue between your teeth and blow air out gently, without voice (as in 'think').",
+	"th": "Put the tip of your tongue between your teeth and blow air out gently, without voice (as in 'think').",
+	"ð":  "Put the tip of your tongue between your teeth and add voice; you should feel a buzz (as in 'this').",
+	"dh": "Put the tip of your tongue between your teeth and add voice; you should feel a buzz (as in 'this').",
+	"r":  "Curl the tongue back without touching the roof of the mouth and round the lips slightly.",
+	"l":  "Press the tongue tip against the ridge behind your upper teeth and let air flow around the sides.",
+	"v":  "Touch your top teeth to your lower lip and add voice; it should buzz, unlike 'w'.",
+	"w":  "Round your lips tightly, then open them quickly; the teeth should not touch the lip.",
+	"æ":  "Open your mouth wide and spread the lips, keeping the tongue low and forward (as in 'cat').",
+	"ae": "Open your mouth wide and spread the lips, keeping the tongue low and forward (as in 'cat').",
+	"ɪ":  "Keep this vowel short and relaxed; don't stretch it into 'ee' (as in 'sit').",
+	"ih": "Keep this vowel short and relaxed; don't stretch it into 'ee' (as in 'sit').",
+	"iː": "Spread your lips as if smiling and hold the sound longer (as in 'seat').",
+	"iy": "Spread your lips as if smiling and hold the sound longer (as in 'seat').",
+	"ʊ":  "Round the lips loosely and keep the sound short (as in 'book').",
+	"uh": "Round the lips loosely and keep the sound short (as in 'book').",
+	"uː": "Push the lips forward into a tight circle and hold the sound (as in 'food').",
+	"uw": "Push the lips forward into a tight circle and hold the sound (as in 'food').",
+	"ʌ":  "Relax the mouth, open it slightly and make a short central sound (as in 'cup').",
+	"ah": "Relax the mouth, open it slightly and make a short central sound (as in 'cup').",
+	"ɜː": "Keep the tongue in the middle of the mouth and hold the sound without rounding (as in 'bird').",
+	"er": "Keep the tongue in the middle of the mouth and hold the sound without rounding (as in 'bird').",
+	"ŋ":  "Raise the back of the tongue to the soft palate and let the sound go through your nose; don't add a 'g'.",
+	"ng": "Raise the back of the tongue to the soft palate and let the sound go through your nose; don't add a 'g'.",
+	"ʃ":  "Round the lips and push air over the raised tongue (as in 'ship').",
+	"sh": "Round the lips and push air over the raised tongue (as in 'ship').",
+	"ʒ":  "Say 'sh' with voice added (as in 'measure').",
+	"zh": "Curl the tongue tip up towards the hard palate and add voice (English 'measure' / pinyin 'zh').",
+	"z":  "Make a hissing 's' and add voice; you should feel a buzz in your throat.",
+	"s":  "Keep the tongue tip behind the upper teeth and push a steady stream of air out.",
+	"n":  "Press the tongue tip behind the upper teeth and let the sound go through your nose.",
+	"t":  "Release the tongue from behind the upper teeth with a clear puff of air; don't drop it at the end of words.",
+	"d":  "Release the tongue from behind the upper teeth with voice; make final 'd' audible.",
+	"k":  "Release the back of the tongue from the soft palate with a puff of air.",
+	"p":  "Close the lips and release them with a clear puff of air.",
+	"b":  "Close the lips and release them with voice, without a strong puff of air.",
+	"h":  "Breathe out gently from the throat before the vowel; don't skip it.",
+	"ch": "Start with the tongue touching the roof of the mouth and release into 'sh' (English 'church' / pinyin 'ch').",
+	"jh": "Say 'ch' with voice added (as in 'judge').",
+	"dʒ": "Say 'ch' with voice added (as in 'judge').",
+	"tʃ": "Start with the tongue touching the roof of the mouth and release into 'sh' (as in 'church').",
+	"ü":  "Say 'ee' and round your lips at the same time without moving the tongue.",
+	"j":  "Press the flat of the tongue against the hard palate and release with little air (pinyin 'j').",
+	"q":  "Like pinyin 'j' but with a strong puff of air.",
+	"x":  "Spread your lips and let air pass over the flat of the tongue near the hard palate (pinyin 'x').",
+	"c":  "Press the tongue tip behind the teeth and release with a strong puff of air, like 'ts' (pinyin 'c').",
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+func TestClassifyPhone(t *testing.T) {
+	tests := []struct {
+		name  string
+		phone soe.PhoneInfoTypeRsp
+		want  string
+	}{
+		{"matched", soe.PhoneInfoTypeRsp{Phone: "θ", ReferencePhone: "θ"}, ""},
+		{"inserted", soe.PhoneInfoTypeRsp{Phone: "ə", Tag: matchTagInserted}, PhoneErrInsertion},
+		{"omitted", soe.PhoneInfoTypeRsp{ReferencePhone: "k", Tag: matchTagOmitted}, PhoneErrOmission},
+		{"mismatch tag", soe.PhoneInfoTypeRsp{Phone: "θ", ReferencePhone: "θ", Tag: matchTagMismatch}, PhoneErrSubstitution},
+		{"different phone", soe.PhoneInfoTypeRsp{Phone: "s", ReferencePhone: "θ"}, PhoneErrSubstitution},
+		{"missing stress", soe.PhoneInfoTypeRsp{Phone: "ɪ", ReferencePhone: "ɪ", Stress: true}, PhoneErrStress},
+		{"extra stress", soe.PhoneInfoTypeRsp{Phone: "ɪ", ReferencePhone: "ɪ", DetectedStress: true}, PhoneErrStress},
+		{"stress on wrong phone", soe.PhoneInfoTypeRsp{Phone: "s", ReferencePhone: "θ", Stress: true}, PhoneErrSubstitution},
+	}
+
+	for _, tt := range tests {
+		if got := classifyPhone(tt.phone); got != tt.want {
+			t.Errorf("classifyPhone(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	words := []soe.WordRsp{
+		{ReferenceWord: "think", PhoneInfo: []soe.PhoneInfoTypeRsp{
+			{Phone: "s", ReferencePhone: "θ", PronAccuracy: 40, Tag: matchTagMismatch},
+			{Phone: "ɪ", ReferencePhone: "ɪ", PronAccuracy: 90},
+			{Phone: "ŋ", ReferencePhone: "ŋ", PronAccuracy: 70},
+			{ReferencePhone: "k", Tag: matchTagOmitted},
+			{Phone: "ə", Tag: matchTagInserted},
+		}},
+		{ReferenceWord: "this", PhoneInfo: []soe.PhoneInfoTypeRsp{
+			{Phone: "d", ReferencePhone: "ð", PronAccuracy: 50},
+			{Phone: "ɪ", ReferencePhone: "ɪ", PronAccuracy: 60},
+			{Phone: "s", ReferencePhone: "s", PronAccuracy: 90, Stress: true},
+		}},
+	}
+
+	d := Diagnose(words)
+
+	if len(d.Phones) != 8 {
+		t.Fatalf("len(Phones) = %d, want 8", len(d.Phones))
+	}
+	if p := d.Phones[5]; p.WordIndex != 1 || p.Word != "this" || p.Error != PhoneErrSubstitution {
+		t.Errorf("Phones[5] = %+v, want substitution in word 1 \"this\"", p)
+	}
+
+	counts := map[string]int{
+		PhoneErrSubstitution: 2,
+		PhoneErrOmission:     1,
+		PhoneErrInsertion:    1,
+		PhoneErrStress:       1,
+	}
+	for kind, want := range counts {
+		if got := d.ErrorCounts[kind]; got != want {
+			t.Errorf("ErrorCounts[%s] = %d, want %d", kind, got, want)
+		}
+	}
+
+	// 错误率相同按平均准确度、音素排序；ɪ 平均 75 不算薄弱，ŋ 被截断
+	weakest := []string{"k", "ə", "θ", "ð", "s"}
+	if len(d.Weakest) != len(weakest) {
+		t.Fatalf("len(Weakest) = %d, want %d", len(d.Weakest), len(weakest))
+	}
+	for i, phone := range weakest {
+		if got := d.Weakest[i].Phone; got != phone {
+			t.Errorf("Weakest[%d] = %s, want %s", i, got, phone)
+		}
+		if len(d.Weakest[i].Tip) == 0 {
+			t.Errorf("Weakest[%d].Tip is empty", i)
+		}
+	}
+	if w := d.Weakest[2]; len(w.Words) != 1 || w.Words[0] != "think" || w.ErrorTypes[PhoneErrSubstitution] != 1 {
+		t.Errorf("Weakest[2] = %+v, want one substitution in \"think\"", w)
+	}
+}
+
+func TestPhoneTip(t *testing.T) {
+	tests := []struct {
+		phone string
+		same  string
+	}{
+		{"TH", "θ"},
+		{"dh", "ð"},
+		{"AE", "æ"},
+		{"unknown", "?"},
+	}
+
+	for _, tt := range tests {
+		if got, want := PhoneTip(tt.phone), PhoneTip(tt.same); got != want {
+			t.Errorf("PhoneTip(%q) = %q, want %q", tt.phone, got, want)
+		}
+	}
+}
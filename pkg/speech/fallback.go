@@ -0,0 +1,149 @@
+package speech
+
+import (
+	"log"
+
+	"lingolift/errno"
+	"lingolift/pkg/upstream"
+)
+
+// Engine 评测引擎：创建识别器使用的语音服务账号和引擎类型，Name 随结果返回给客户端
+type Engine struct {
+	Name string
+
+	// ServerEngineType 为空时使用请求的引擎类型
+	ServerEngineType string
+
+	NewRecognizer RecognizerFactory
+
+	// Breaker 引擎上游的熔断器，为 nil 时不熔断
+	Breaker *upstream.Breaker
+}
+
+// Engine 当前使用的引擎名称
+func (s *Session) Engine() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.engines[s.engine].Name
+}
+
+// canFallback 错误是否可以通过切换引擎解决，音频、参考文本等客户端原因的错误换引擎也无法解决
+func canFallback(err error) bool {
+	return upstream.Error(err).ErrType != errno.ErrTypeSender
+}
+
+// nextEngine 从失败的引擎切换到下一个引擎，没有可用引擎时返回 false
+// 其他段已从该引擎切换过时沿用当前引擎，changed 为 false。调用方需持有 mu。
+func (s *Session) nextEngine(failed string) (changed, ok bool) {
+	if s.engines[s.engine].Name != failed {
+		return false, true
+	}
+	if s.engine+1 >= len(s.engines) {
+		return false, false
+	}
+	s.engine++
+	return true, true
+}
+
+// notifyEngine 通知客户端已切换到当前引擎
+func (s *Session) notifyEngine(failed string, err error) {
+	name := s.Engine()
+	log.Printf("引擎 %s 失败，切换到 %s: %v", failed, name, err)
+	response := AssessmentResponse{
+		Status:    "fallback",
+		SessionID: s.ID,
+		Engine:    name,
+	}
+	writeResponse(s.Conn, &s.writeMu, response)
+	s.publish(response)
+}
+
+// onFail 识别器失败的回调，由 SDK 的接收协程同步调用，不能阻塞
+func (s *Session) onFail(l *StreamListener, err error) bool {
+	return s.fallback(l.index, l, err)
+}
+
+// fallback 段的识别器失败时切换到下一个引擎，在后台重新启动该段，无法切换时返回 false
+// 切换期间客户端音频只缓存不发送，新的识别器启动后重放。该段正在切换时直接返回 true。
+func (s *Session) fallback(i int, failed *StreamListener, err error) bool {
+	if !canFallback(err) {
+		return false
+	}
+
+	s.mu.Lock()
+	if i >= len(s.listeners) || s.listeners[i] != failed {
+		s.mu.Unlock()
+		return false
+	}
+	old := s.recognizers[i]
+	if old == nil {
+		s.mu.Unlock()
+		return true
+	}
+	changed, ok := s.nextEngine(failed.Engine)
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	s.recognizers[i] = nil
+	s.pending++
+	s.switches++
+	s.mu.Unlock()
+
+	go s.restart(i, failed, old, changed, err)
+	return true
+}
+
+// restart 使用新的引擎重新启动段的识别器，替换后重放该段已接收的音频
+// 该段已结束时写完后停止识别器等待结果。启动失败时由失败的监听器上报错误。
+func (s *Session) restart(i int, failed *StreamListener, old Recognizer, changed bool, cause error) {
+	defer func() {
+		s.mu.Lock()
+		s.pending--
+		s.switched.Broadcast()
+		s.mu.Unlock()
+	}()
+
+	go old.Stop()
+	if changed {
+		s.notifyEngine(failed.Engine, cause)
+	}
+
+	listener, recognizer, err := s.startSegment(i, true)
+	if err != nil {
+		log.Printf("切换引擎后启动识别器失败: %v", err)
+		failed.fail(err)
+		return
+	}
+
+	// 替换和重放期间不写入客户端的新音频，避免交错
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
+
+	s.mu.Lock()
+	s.listeners[i] = listener
+	s.recognizers[i] = recognizer
+	end := len(s.chunks)
+	if i+1 < len(s.starts) {
+		end = s.starts[i+1]
+	}
+	chunks := s.chunks[s.starts[i]:end]
+	live := i == len(s.starts)-1 && !s.finished
+	s.mu.Unlock()
+	failed.detach()
+
+	log.Printf("重放第 %d 段音频: %d 块", i+1, len(chunks))
+	for _, data := range chunks {
+		if err = recognizer.Write(data); err != nil {
+			log.Printf("重放音频失败: %v", err)
+			if !s.fallback(i, listener, err) {
+				listener.fail(err)
+			}
+			return
+		}
+	}
+
+	if !live {
+		go recognizer.Stop()
+	}
+}
@@ -0,0 +1,283 @@
+package speech
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"lingolift/errno"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+// fakeEngine 模拟上游引擎的行为
+type fakeEngine struct {
+	startErr   error         // Start 返回的错误
+	startDelay time.Duration // Start 耗时
+	failAfter  int           // 收到第 n 块音频后失败，0 表示不失败
+	failOnStop bool          // 音频结束后失败
+	hang       bool          // 音频结束后不返回结果
+}
+
+// fakeRecognizer 按 fakeEngine 回调监听器，结束时以收到的音频块数作为得分
+type fakeRecognizer struct {
+	engine   fakeEngine
+	listener *StreamListener
+
+	mu      sync.Mutex
+	chunks  int
+	failed  bool
+	stopped bool
+}
+
+func (e fakeEngine) factory() RecognizerFactory {
+	return func(req *AssessmentRequest, listener *StreamListener) (Recognizer, error) {
+		return &fakeRecognizer{engine: e, listener: listener}, nil
+	}
+}
+
+func (r *fakeRecognizer) Start() error {
+	time.Sleep(r.engine.startDelay)
+	if r.engine.startErr != nil {
+		return r.engine.startErr
+	}
+	r.listener.OnRecognitionStart(&soe.SpeakingAssessmentResponse{})
+	return nil
+}
+
+func (r *fakeRecognizer) Write(data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed || r.stopped {
+		return errors.New("recognizer not running")
+	}
+
+	r.chunks++
+	if r.chunks == r.engine.failAfter {
+		// SDK 在接收协程中同步调用 OnFail
+		r.failed = true
+		go r.listener.OnFail(&soe.SpeakingAssessmentResponse{Code: 5000},
+			errors.New("VoiceID: v1, error code 5000, message: server error"))
+	}
+	return nil
+}
+
+func (r *fakeRecognizer) Stop() error {
+	r.mu.Lock()
+	if r.failed || r.stopped {
+		r.mu.Unlock()
+		return errors.New("recognizer is not running")
+	}
+	r.stopped = true
+	chunks := r.chunks
+	r.mu.Unlock()
+
+	switch {
+	case r.engine.hang:
+	case r.engine.failOnStop:
+		r.listener.OnFail(&soe.SpeakingAssessmentResponse{Code: 5000},
+			errors.New("VoiceID: v1, error code 5000, message: server error"))
+	default:
+		r.listener.OnRecognitionComplete(&soe.SpeakingAssessmentResponse{Result: soe.SentenceInfo{
+			SuggestedScore: float64(chunks),
+			Words:          []soe.WordRsp{{Word: "hello", ReferenceWord: "hello"}},
+		}})
+	}
+	return nil
+}
+
+// fakeSession 使用模拟引擎创建会话，记录发送给客户端的响应状态
+type fakeSession struct {
+	*Session
+
+	mu       sync.Mutex
+	statuses []string
+}
+
+func newFakeSession(engines ...fakeEngine) *fakeSession {
+	var list []*Engine
+	for i, e := range engines {
+		list = append(list, &Engine{Name: fmt.Sprintf("e%d", i), NewRecognizer: e.factory()})
+	}
+
+	req := &AssessmentRequest{RefText: "hello", ServerEngineType: "16k_en"}
+	fs := &fakeSession{Session: NewSession(nil, req, list)}
+	fs.OnResponse = func(s *Session, response AssessmentResponse) {
+		fs.mu.Lock()
+		defer fs.mu.Unlock()
+		fs.statuses = append(fs.statuses, response.Status)
+	}
+	return fs
+}
+
+// run 写入 chunks 块音频后结束，返回最大的写入耗时
+func (fs *fakeSession) run(t *testing.T, chunks int) time.Duration {
+	t.Helper()
+	if err := fs.Start(); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	var slowest time.Duration
+	for i := 0; i < chunks; i++ {
+		begin := time.Now()
+		if err := fs.Write(make([]byte, 320)); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+		if d := time.Since(begin); d > slowest {
+			slowest = d
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		fs.Finish()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Finish() did not return")
+	}
+	return slowest
+}
+
+func (fs *fakeSession) count(status string) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var n int
+	for _, s := range fs.statuses {
+		if s == status {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSessionFallback(t *testing.T) {
+	serverErr := errors.New("voice_id: v1, code: 5000, message: server error")
+
+	tests := []struct {
+		name      string
+		engines   []fakeEngine
+		engine    string // 产生结果的引擎
+		fallbacks int
+	}{
+		{"no failure", []fakeEngine{{}, {}}, "e0", 0},
+		{"start failure", []fakeEngine{{startErr: serverErr}, {}}, "e1", 1},
+		{"failure while streaming", []fakeEngine{{failAfter: 3}, {}}, "e1", 1},
+		{"failure during replay", []fakeEngine{{failAfter: 3}, {failAfter: 2}, {}}, "e2", 2},
+		{"failure after finish", []fakeEngine{{failOnStop: true}, {}}, "e1", 1},
+		{"chained failures after finish", []fakeEngine{{failOnStop: true}, {failOnStop: true}, {}}, "e2", 2},
+		{"slow fallback start", []fakeEngine{{failAfter: 2}, {startDelay: 200 * time.Millisecond}}, "e1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const chunks = 8
+			fs := newFakeSession(tt.engines...)
+			slowest := fs.run(t, chunks)
+
+			select {
+			case err := <-fs.ErrorChan:
+				t.Fatalf("session error: %v", err)
+			default:
+			}
+			select {
+			case <-fs.Complete:
+			default:
+				t.Fatal("session did not complete")
+			}
+
+			result := fs.Result()
+			if result == nil {
+				t.Fatal("Result() = nil")
+			}
+			if result.Engine != tt.engine {
+				t.Errorf("Engine = %q, want %q", result.Engine, tt.engine)
+			}
+			// 每块音频都写入且只写入产生结果的识别器一次
+			if result.OverallScore != chunks {
+				t.Errorf("OverallScore = %v, want %d chunks replayed", result.OverallScore, chunks)
+			}
+			if got := fs.count("fallback"); got != tt.fallbacks {
+				t.Errorf("fallback responses = %d, want %d", got, tt.fallbacks)
+			}
+			if got := fs.count("start"); got != 1 {
+				t.Errorf("start responses = %d, want 1", got)
+			}
+			// 切换引擎期间客户端音频只缓存，不等待新的识别器启动
+			if slowest > 100*time.Millisecond {
+				t.Errorf("slowest Write() = %v, want it not to wait for the fallback engine", slowest)
+			}
+		})
+	}
+}
+
+func TestSessionFallbackError(t *testing.T) {
+	tests := []struct {
+		name    string
+		engines []fakeEngine
+		code    string
+	}{
+		{"no fallback engine", []fakeEngine{{failOnStop: true}}, "UpstreamFailed"},
+		{"all engines fail", []fakeEngine{{failOnStop: true}, {failOnStop: true}}, "UpstreamFailed"},
+		{"fallback engine cannot start", []fakeEngine{{failOnStop: true}, {
+			startErr: errors.New("voice_id: v1, code: 4002, message: auth failed"),
+		}}, "UpstreamAuthFailed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newFakeSession(tt.engines...)
+			fs.run(t, 4)
+
+			select {
+			case err := <-fs.ErrorChan:
+				if got := NewErrorResponse(err).Code; got != tt.code {
+					t.Errorf("error code = %s, want %s", got, tt.code)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no session error")
+			}
+			if got := fs.count("error"); got != 1 {
+				t.Errorf("error responses = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestSessionResultTimeout(t *testing.T) {
+	defer func(d time.Duration) { resultTimeout = d }(resultTimeout)
+	resultTimeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		engines []fakeEngine
+	}{
+		{"listener never completes", []fakeEngine{{hang: true}}},
+		{"fallback listener never completes", []fakeEngine{{failOnStop: true}, {hang: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newFakeSession(tt.engines...)
+			fs.run(t, 4)
+
+			select {
+			case err := <-fs.ErrorChan:
+				var e errno.Err
+				if !errors.As(err, &e) || e.Code != errno.ErrUpstreamTimeout.Code {
+					t.Errorf("session error = %v, want %s", err, errno.ErrUpstreamTimeout.Code)
+				}
+			default:
+				t.Fatal("no session error")
+			}
+			if got := fs.count("error"); got != 1 {
+				t.Errorf("error responses = %d, want 1", got)
+			}
+		})
+	}
+}
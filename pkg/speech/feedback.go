@@ -0,0 +1,274 @@
+package speech
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultFeedbackDir 反馈模板目录，每个语言一个 <locale>.yml 文件
+	DefaultFeedbackDir = "locales/feedback"
+
+	// DefaultFeedbackLocale 客户端未指定或不支持的语言使用的模板
+	DefaultFeedbackLocale = "en"
+
+	// 除总体评价外最多返回的反馈条数
+	maxFeedbackItems = 4
+
+	// 相邻单词间隔超过该值（毫秒）视为停顿
+	feedbackPauseMs = 600
+
+	// 单词准确度低于该值且没有明确的音素错误时提示发音不清
+	feedbackWordAccuracy = 60
+
+	// 流畅度、完整度低于该值时给出整体提示（0-1）
+	lowFluency    = 0.6
+	lowCompletion = 0.8
+)
+
+// 模板名称，对应模板文件中的键
+const (
+	feedbackExcellent     = "overall_excellent"
+	feedbackGood          = "overall_good"
+	feedbackFair          = "overall_fair"
+	feedbackPoor          = "overall_poor"
+	feedbackPhoneOmission = "phone_omission"
+	feedbackPhoneFinal    = "phone_omission_final"
+	feedbackPhoneSubst    = "phone_substitution"
+	feedbackPhoneStress   = "phone_stress"
+	feedbackWordOmitted   = "word_omitted"
+	feedbackWordInserted  = "word_inserted"
+	feedbackWordUnclear   = "word_unclear"
+	feedbackPause         = "pause"
+	feedbackLowFluency    = "low_fluency"
+	feedbackLowCompletion = "low_completion"
+)
+
+// FeedbackConfig 反馈模板配置
+type FeedbackConfig struct {
+	Dir           string `yaml:"dir"`
+	DefaultLocale string `yaml:"default_locale"`
+
+	locales map[string]*Feedback
+}
+
+// Load 加载模板目录下所有语言的模板
+func (c *FeedbackConfig) Load() error {
+	if len(c.Dir) == 0 {
+		c.Dir = DefaultFeedbackDir
+	}
+	if len(c.DefaultLocale) == 0 {
+		c.DefaultLocale = DefaultFeedbackLocale
+	}
+
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.yml"))
+	if err != nil {
+		return err
+	}
+
+	c.locales = map[string]*Feedback{}
+	for _, file := range files {
+		locale := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		feedback, err := loadFeedback(locale, file)
+		if err != nil {
+			return err
+		}
+		c.locales[strings.ToLower(locale)] = feedback
+	}
+
+	fallback, ok := c.locales[strings.ToLower(c.DefaultLocale)]
+	if !ok {
+		return fmt.Errorf("feedback templates for default locale %q not found in %s", c.DefaultLocale, c.Dir)
+	}
+	for _, feedback := range c.locales {
+		if feedback != fallback {
+			feedback.fallback = fallback
+		}
+	}
+
+	return nil
+}
+
+// Lookup 返回语言对应的模板，依次尝试 zh-CN、zh，找不到时使用默认语言
+func (c *FeedbackConfig) Lookup(locale string) *Feedback {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	for len(locale) > 0 {
+		if feedback, ok := c.locales[locale]; ok {
+			return feedback
+		}
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return c.locales[strings.ToLower(c.DefaultLocale)]
+}
+
+// Feedback 单个语言的反馈模板
+type Feedback struct {
+	Locale    string
+	templates map[string]*template.Template
+	fallback  *Feedback
+}
+
+// feedbackData 模板参数
+type feedbackData struct {
+	Word     string
+	Prev     string
+	Phone    string
+	Expected string
+	Score    float64
+	Level    string
+}
+
+func loadFeedback(locale, filename string) (*Feedback, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var texts map[string]string
+	if err = yaml.Unmarshal(content, &texts); err != nil {
+		return nil, fmt.Errorf("parse feedback templates %s: %w", filename, err)
+	}
+
+	f := &Feedback{Locale: locale, templates: map[string]*template.Template{}}
+	for key, text := range texts {
+		tmpl, err := template.New(key).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse feedback template %s in %s: %w", key, filename, err)
+		}
+		f.templates[key] = tmpl
+	}
+
+	return f, nil
+}
+
+// Apply 生成反馈并写入结果
+func (f *Feedback) Apply(result *SOEResult) {
+	if f == nil || result == nil {
+		return
+	}
+	result.Feedback = f.Generate(result)
+}
+
+// Generate 根据最终结果生成反馈：一句总体评价，加上最多 maxFeedbackItems 条具体问题
+// 具体问题按音素错误、漏读、停顿、多读、发音不清的顺序选取。
+func (f *Feedback) Generate(result *SOEResult) []string {
+	score := result.Score
+	if score == 0 {
+		score = result.OverallScore
+	}
+
+	summary := feedbackPoor
+	switch {
+	case score >= 85:
+		summary = feedbackExcellent
+	case score >= 70:
+		summary = feedbackGood
+	case score >= 50:
+		summary = feedbackFair
+	}
+
+	var (
+		phones, omitted, pauses, inserted, unclear []string
+		prev                                       string
+		prevEnd                                    int64
+	)
+	for _, w := range result.Words {
+		switch w.Tag {
+		case matchTagOmitted:
+			omitted = f.add(omitted, feedbackWordOmitted, feedbackData{Word: w.ReferenceWord})
+			continue
+		case matchTagInserted:
+			inserted = f.add(inserted, feedbackWordInserted, feedbackData{Word: w.Word})
+			continue
+		}
+
+		if len(prev) > 0 && w.Mbtm-prevEnd > feedbackPauseMs {
+			pauses = f.add(pauses, feedbackPause, feedbackData{Word: w.ReferenceWord, Prev: prev})
+		}
+		prev, prevEnd = w.ReferenceWord, w.Metm
+
+		if key, data, ok := phoneFeedback(w.ReferenceWord, w.PhoneInfo); ok {
+			phones = f.add(phones, key, data)
+		} else if w.PronAccuracy >= 0 && w.PronAccuracy < feedbackWordAccuracy {
+			unclear = f.add(unclear, feedbackWordUnclear, feedbackData{Word: w.ReferenceWord})
+		}
+	}
+
+	// 没有具体停顿、漏读时给出整体提示
+	if len(pauses) == 0 && result.PronFluency > 0 && result.PronFluency < lowFluency {
+		pauses = f.add(pauses, feedbackLowFluency, feedbackData{})
+	}
+	if len(omitted) == 0 && result.PronCompletion > 0 && result.PronCompletion < lowCompletion {
+		omitted = f.add(omitted, feedbackLowCompletion, feedbackData{})
+	}
+
+	sentences := f.add(nil, summary, feedbackData{Score: score, Level: result.Level})
+	for _, group := range [][]string{phones, omitted, pauses, inserted, unclear} {
+		for _, s := range group {
+			if len(sentences) > maxFeedbackItems {
+				return sentences
+			}
+			sentences = append(sentences, s)
+		}
+	}
+
+	return sentences
+}
+
+// add 渲染模板并追加到列表，当前语言缺少该模板时使用默认语言
+func (f *Feedback) add(list []string, key string, data feedbackData) []string {
+	tmpl, ok := f.templates[key]
+	if !ok && f.fallback != nil {
+		tmpl, ok = f.fallback.templates[key]
+	}
+	if !ok {
+		return list
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("渲染反馈模板失败: locale=%s, key=%s, err=%v", f.Locale, key, err)
+		return list
+	}
+
+	return append(list, strings.TrimSpace(buf.String()))
+}
+
+// phoneFeedback 返回单词中第一个音素错误对应的模板
+func phoneFeedback(word string, phones []soe.PhoneInfoTypeRsp) (string, feedbackData, bool) {
+	last := -1
+	for i, p := range phones {
+		if p.Tag != matchTagInserted {
+			last = i
+		}
+	}
+
+	for i, p := range phones {
+		data := feedbackData{Word: word, Phone: p.Phone, Expected: p.ReferencePhone}
+		switch classifyPhone(p) {
+		case PhoneErrOmission:
+			if i == last {
+				return feedbackPhoneFinal, data, true
+			}
+			return feedbackPhoneOmission, data, true
+		case PhoneErrSubstitution:
+			return feedbackPhoneSubst, data, true
+		case PhoneErrStress:
+			return feedbackPhoneStress, data, true
+		}
+	}
+
+	return "", feedbackData{}, false
+}
@@ -0,0 +1,185 @@
+package speech
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+const testFeedbackEn = `
+overall_excellent: "excellent {{.Score}}"
+overall_good: "good {{.Score}}"
+overall_fair: "fair"
+overall_poor: "poor {{.Level}}"
+phone_omission: "omission {{.Word}} {{.Expected}}"
+phone_omission_final: "final {{.Word}} {{.Expected}}"
+phone_substitution: "substitution {{.Word}} {{.Phone}} {{.Expected}}"
+phone_stress: "stress {{.Word}}"
+word_omitted: "omitted {{.Word}}"
+word_inserted: "inserted {{.Word}}"
+word_unclear: "unclear {{.Word}}"
+pause: "pause {{.Prev}} {{.Word}}"
+low_fluency: "low fluency"
+low_completion: "low completion"
+`
+
+// 中文模板只有总体评价，其余使用默认语言
+const testFeedbackZh = `
+overall_excellent: "优秀 {{.Score}}"
+`
+
+func loadTestFeedback(t *testing.T) *FeedbackConfig {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{"en.yml": testFeedbackEn, "zh.yml": testFeedbackZh}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &FeedbackConfig{Dir: dir}
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	return c
+}
+
+func TestFeedbackLookup(t *testing.T) {
+	c := loadTestFeedback(t)
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"zh", "zh"},
+		{"zh-CN", "zh"},
+		{"zh_tw", "zh"},
+		{"EN-us", "en"},
+		{"fr", "en"},
+		{"", "en"},
+	}
+
+	for _, tt := range tests {
+		if got := c.Lookup(tt.locale).Locale; got != tt.want {
+			t.Errorf("Lookup(%q) = %s, want %s", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestFeedbackLoadMissingDefault(t *testing.T) {
+	c := &FeedbackConfig{Dir: t.TempDir(), DefaultLocale: "en"}
+	if err := c.Load(); err == nil {
+		t.Error("Load() without default locale templates = nil, want error")
+	}
+}
+
+func TestFeedbackGenerate(t *testing.T) {
+	c := loadTestFeedback(t)
+
+	tests := []struct {
+		name   string
+		locale string
+		result SOEResult
+		want   []string
+	}{
+		{
+			name:   "overall only",
+			locale: "en",
+			result: SOEResult{OverallScore: 90},
+			want:   []string{"excellent 90"},
+		},
+		{
+			name:   "final score takes precedence",
+			locale: "en",
+			result: SOEResult{OverallScore: 90, Score: 72.5},
+			want:   []string{"good 72.5"},
+		},
+		{
+			name:   "ordered and limited",
+			locale: "en",
+			result: SOEResult{OverallScore: 60, Words: []soe.WordRsp{
+				{ReferenceWord: "hello", Mbtm: 0, Metm: 500, PronAccuracy: 90},
+				{ReferenceWord: "world", Mbtm: 1200, Metm: 1600, PronAccuracy: 90},
+				{ReferenceWord: "apple", Tag: matchTagOmitted},
+				{Word: "um", Tag: matchTagInserted},
+				{ReferenceWord: "think", Mbtm: 1700, Metm: 2000, PronAccuracy: 70, PhoneInfo: []soe.PhoneInfoTypeRsp{
+					{Phone: "s", ReferencePhone: "θ"},
+				}},
+				{ReferenceWord: "fast", Mbtm: 2000, Metm: 2300, PronAccuracy: 40},
+			}},
+			want: []string{"fair", "substitution think s θ", "omitted apple", "pause hello world", "inserted um"},
+		},
+		{
+			name:   "final phone omission and overall hints",
+			locale: "en",
+			result: SOEResult{Score: 40, Level: "A1", PronFluency: 0.4, PronCompletion: 0.5, Words: []soe.WordRsp{
+				{ReferenceWord: "cat", PronAccuracy: 70, PhoneInfo: []soe.PhoneInfoTypeRsp{
+					{Phone: "k", ReferencePhone: "k"},
+					{Phone: "æ", ReferencePhone: "æ"},
+					{ReferencePhone: "t", Tag: matchTagOmitted},
+					{Phone: "ə", Tag: matchTagInserted},
+				}},
+			}},
+			want: []string{"poor A1", "final cat t", "low completion", "low fluency"},
+		},
+		{
+			name:   "phone omission and stress",
+			locale: "en",
+			result: SOEResult{OverallScore: 75, Words: []soe.WordRsp{
+				{ReferenceWord: "cat", Mbtm: 0, Metm: 300, PronAccuracy: 70, PhoneInfo: []soe.PhoneInfoTypeRsp{
+					{ReferencePhone: "k", Tag: matchTagOmitted},
+					{Phone: "æ", ReferencePhone: "æ"},
+					{Phone: "t", ReferencePhone: "t"},
+				}},
+				{ReferenceWord: "record", Mbtm: 500, Metm: 900, PronAccuracy: 80, PhoneInfo: []soe.PhoneInfoTypeRsp{
+					{Phone: "r", ReferencePhone: "r"},
+					{Phone: "ɛ", ReferencePhone: "ɛ", Stress: true},
+				}},
+			}},
+			want: []string{"good 75", "omission cat k", "stress record"},
+		},
+		{
+			name:   "fallback to default locale",
+			locale: "zh-CN",
+			result: SOEResult{OverallScore: 90, Words: []soe.WordRsp{
+				{ReferenceWord: "hello", Mbtm: 0, Metm: 500, PronAccuracy: 90},
+				{ReferenceWord: "world", Mbtm: 1200, Metm: 1600, PronAccuracy: 90},
+			}},
+			want: []string{"优秀 90", "pause hello world"},
+		},
+	}
+
+	for _, tt := range tests {
+		result := tt.result
+		c.Lookup(tt.locale).Apply(&result)
+		if !reflect.DeepEqual(result.Feedback, tt.want) {
+			t.Errorf("%s: Feedback = %q, want %q", tt.name, result.Feedback, tt.want)
+		}
+	}
+}
+
+// 检查仓库自带的模板完整可用
+func TestFeedbackLocales(t *testing.T) {
+	c := &FeedbackConfig{Dir: filepath.Join("..", "..", DefaultFeedbackDir)}
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	keys := []string{
+		feedbackExcellent, feedbackGood, feedbackFair, feedbackPoor,
+		feedbackPhoneOmission, feedbackPhoneFinal, feedbackPhoneSubst, feedbackPhoneStress,
+		feedbackWordOmitted, feedbackWordInserted, feedbackWordUnclear,
+		feedbackPause, feedbackLowFluency, feedbackLowCompletion,
+	}
+	for locale, f := range c.locales {
+		for _, key := range keys {
+			if _, ok := f.templates[key]; !ok {
+				t.Errorf("locale %s is missing template %s", locale, key)
+			}
+		}
+	}
+}
@@ -1,137 +1,352 @@
-package speech
-
-import (
-	"log"
-	"time"
-
-	"github.com/gorilla/websocket"
-	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
-)
-
-// StreamListener
-type StreamListener struct {
-	Conn       *websocket.Conn
-	ResultChan chan *SOEResult
-	ErrorChan  chan error
-	Complete   chan struct{}
-}
-
-func NewStreamListener(conn *websocket.Conn) *StreamListener {
-	return &StreamListener{
-		Conn:       conn,
-		ResultChan: make(chan *SOEResult, 10),
-		ErrorChan:  make(chan error, 1),
-		Complete:   make(chan struct{}),
-	}
-}
-
-func (l *StreamListener) OnRecognitionStart(response *soe.SpeakingAssessmentResponse) {
-	log.Printf("OnRecognitionStart: %s", response.VoiceID)
-	l.sendResponse("start", nil, nil)
-}
-
-func (l *StreamListener) OnIntermediateResults(response *soe.SpeakingAssessmentResponse) {
-	log.Printf("OnIntermediateResults: 整体得分=%.2f, 准确率=%.2f, 流畅度=%.2f",
-		response.Result.SuggestedScore,
-		response.Result.PronAccuracy,
-		response.Result.PronFluency)
-
-	if len(response.Result.Words) > 0 {
-		result := &SOEResult{
-			OverallScore:   response.Result.SuggestedScore,
-			Words:          response.Result.Words,
-			PronAccuracy:   response.Result.PronAccuracy,
-			PronFluency:    response.Result.PronFluency,
-			PronCompletion: response.Result.PronCompletion,
-		}
-		l.ResultChan <- result
-		l.sendResponse("intermediate", result, nil)
-	}
-}
-
-func (l *StreamListener) OnRecognitionComplete(response *soe.SpeakingAssessmentResponse) {
-	log.Printf("语音识别评测结果完成: 整体得分=%.2f, 准确率=%.2f, 流畅度=%.2f",
-		response.Result.SuggestedScore,
-		response.Result.PronAccuracy,
-		response.Result.PronFluency)
-
-	if len(response.Result.Words) > 0 {
-		result := &SOEResult{
-			OverallScore:   response.Result.SuggestedScore,
-			Words:          response.Result.Words,
-			PronAccuracy:   response.Result.PronAccuracy,
-			PronFluency:    response.Result.PronFluency,
-			PronCompletion: response.Result.PronCompletion,
-		}
-		l.ResultChan <- result
-		l.sendResponse("complete", result, nil)
-	}
-
-	close(l.Complete)
-}
-
-func (l *StreamListener) OnFail(response *soe.SpeakingAssessmentResponse, err error) {
-	log.Printf("OnFail: %v", err)
-	l.ErrorChan <- err
-	l.sendResponse("error", nil, err)
-	close(l.Complete)
-}
-
-func (l *StreamListener) sendResponse(status string, result *SOEResult, err error) {
-	log.Println("准备发送响应:", status)
-	if l.Conn == nil {
-		log.Println("WebSocket connection is nil")
-		return
-	}
-
-	// 使用写锁防止并发写入
-	l.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	defer l.Conn.SetWriteDeadline(time.Time{})
-
-	response := AssessmentResponse{
-		Status: status,
-		Result: result,
-	}
-	if err != nil {
-		response.Error = err.Error()
-	}
-
-	if err := l.Conn.WriteJSON(response); err != nil {
-		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-			log.Println("WebSocket closed normally")
-		} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-			log.Printf("Unexpected WebSocket close: %v", err)
-		} else {
-			log.Printf("WebSocket write error: %v", err)
-		}
-	}
-}
-
-// AssessmentRequest 评测请求参数
-type AssessmentRequest struct {
-	RefText          string  `json:"ref_text" validate:"required"`
-	ServerEngineType string  `json:"server_engine_type" default:"16k_en"`
-	ScoreCoeff       float64 `json:"score_coeff" default:"1.1"`
-	EvalMode         int64   `json:"eval_mode" default:"0"`
-	TextMode         int64   `json:"text_mode" default:"0"`
-	IsSaveAudioFile  bool    `json:"is_save_audio_file" default:"false"`
-}
-
-func (req *AssessmentRequest) Validator() error {
-	// 可以添加其他参数的验证逻辑
-	return nil
-}
-
-type AssessmentResponse struct {
-	Status string     `json:"status"`
-	Result *SOEResult `json:"result,omitempty"`
-	Error  string     `json:"error,omitempty"`
-}
-
-type SOEResult struct {
-	OverallScore   float64       `json:"overall_score,omitempty"`
-	Words          []soe.WordRsp `json:"words,omitempty"`
-	PronAccuracy   float64       `json:"pron_accuracy,omitempty"`
-	PronFluency    float64       `json:"pron_fluency,omitempty"`
-	PronCompletion float64       `json:"pron_completion,omitempty"`
-}
+package speech
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/audio"
+	"lingolift/pkg/upstream"
+
+	"github.com/gorilla/websocket"
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+// StreamListener
+type StreamListener struct {
+	Conn       *websocket.Conn
+	ResultChan chan *SOEResult
+	ErrorChan  chan error
+	Complete   chan struct{}
+
+	// SessionID 所属会话的 ID，随每条响应返回给客户端
+	SessionID string
+
+	// Segment 分段评测时对应的段，整段评测时为 nil
+	Segment *SegmentResult
+
+	// Text 规范化后的参考文本，用于把结果映射回原始单词
+	Text *NormalizedText
+
+	// Scorer 按评分配置计算最终得分和等级
+	Scorer *Scorer
+
+	// Feedback 生成文字反馈的模板
+	Feedback *Feedback
+
+	// Engine 识别器使用的引擎名称，随结果返回
+	Engine string
+
+	// hideIntermediate 不向客户端发送中间结果和分段结果，监控端仍可收到
+	hideIntermediate bool
+
+	// detached 识别器启动超时或切换引擎后被放弃，不再向客户端和会话上报
+	detached atomic.Bool
+
+	// replay 切换引擎后重放音频的监听器，不再通知客户端开始
+	replay bool
+
+	// index 监听器对应的段，onFail 返回 true 时已切换到其他引擎，不再上报错误
+	index  int
+	onFail func(l *StreamListener, err error) bool
+
+	onFinal      func(result *SOEResult)
+	onResponse   func(response AssessmentResponse)
+	writeMu      *sync.Mutex
+	mu           sync.Mutex
+	latest       *SOEResult
+	completeOnce sync.Once
+}
+
+func NewStreamListener(conn *websocket.Conn) *StreamListener {
+	return &StreamListener{
+		Conn:       conn,
+		ResultChan: make(chan *SOEResult, 10),
+		ErrorChan:  make(chan error, 1),
+		Complete:   make(chan struct{}),
+		writeMu:    &sync.Mutex{},
+	}
+}
+
+// Result 返回最近一次收到的评测结果
+func (l *StreamListener) Result() *SOEResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.latest
+}
+
+func (l *StreamListener) OnRecognitionStart(response *soe.SpeakingAssessmentResponse) {
+	log.Printf("OnRecognitionStart: %s", response.VoiceID)
+
+	// 分段评测只在第一段开始时通知客户端
+	if (l.Segment != nil && l.Segment.Index > 0) || l.replay {
+		return
+	}
+	l.sendResponse("start", nil, nil)
+}
+
+func (l *StreamListener) OnIntermediateResults(response *soe.SpeakingAssessmentResponse) {
+	log.Printf("OnIntermediateResults: 整体得分=%.2f, 准确率=%.2f, 流畅度=%.2f",
+		response.Result.SuggestedScore,
+		response.Result.PronAccuracy,
+		response.Result.PronFluency)
+
+	if len(response.Result.Words) > 0 {
+		result := &SOEResult{
+			OverallScore:   response.Result.SuggestedScore,
+			Words:          l.Text.Remap(response.Result.Words),
+			PronAccuracy:   response.Result.PronAccuracy,
+			PronFluency:    response.Result.PronFluency,
+			PronCompletion: response.Result.PronCompletion,
+		}
+		l.publish(result)
+		l.sendResponse("intermediate", result, nil)
+	}
+}
+
+func (l *StreamListener) OnRecognitionComplete(response *soe.SpeakingAssessmentResponse) {
+	log.Printf("语音识别评测结果完成: 整体得分=%.2f, 准确率=%.2f, 流畅度=%.2f",
+		response.Result.SuggestedScore,
+		response.Result.PronAccuracy,
+		response.Result.PronFluency)
+
+	// 已放弃的识别器不产生结果
+	if len(response.Result.Words) > 0 && !l.detached.Load() {
+		result := &SOEResult{
+			OverallScore:   response.Result.SuggestedScore,
+			Words:          l.Text.Remap(response.Result.Words),
+			PronAccuracy:   response.Result.PronAccuracy,
+			PronFluency:    response.Result.PronFluency,
+			PronCompletion: response.Result.PronCompletion,
+			Engine:         l.Engine,
+		}
+		l.publish(result)
+
+		// 分段评测的最终结果由 Session 汇总后发送
+		if l.Segment != nil {
+			l.Segment.Result = result
+			l.sendResponse("segment", result, nil)
+		} else {
+			finalize(result, l.Scorer, l.Feedback)
+			if l.onFinal != nil {
+				l.onFinal(result)
+			}
+			l.sendResponse("complete", result, nil)
+		}
+	}
+
+	l.completeOnce.Do(func() { close(l.Complete) })
+}
+
+func (l *StreamListener) OnFail(response *soe.SpeakingAssessmentResponse, err error) {
+	log.Printf("OnFail: %v", err)
+	if !l.detached.Load() && (l.onFail == nil || !l.onFail(l, err)) {
+		l.fail(err)
+	}
+	l.completeOnce.Do(func() { close(l.Complete) })
+}
+
+// fail 向客户端上报错误
+func (l *StreamListener) fail(err error) {
+	select {
+	case l.ErrorChan <- err:
+	default:
+	}
+	l.sendResponse("error", nil, err)
+}
+
+// finalize 为最终结果生成音素诊断、得分等级和文字反馈
+func finalize(result *SOEResult, scorer *Scorer, feedback *Feedback) {
+	result.Diagnostics = Diagnose(result.Words)
+	scorer.Apply(result)
+	feedback.Apply(result)
+}
+
+// publish 记录最新结果，ResultChan 无人读取时丢弃，避免阻塞 SDK 的事件分发
+func (l *StreamListener) publish(result *SOEResult) {
+	l.mu.Lock()
+	l.latest = result
+	l.mu.Unlock()
+
+	select {
+	case l.ResultChan <- result:
+	default:
+	}
+}
+
+// detach 放弃监听器，之后的回调不再发送响应
+func (l *StreamListener) detach() {
+	l.detached.Store(true)
+}
+
+func (l *StreamListener) sendResponse(status string, result *SOEResult, err error) {
+	if l.detached.Load() {
+		return
+	}
+	log.Println("准备发送响应:", status)
+
+	response := AssessmentResponse{
+		Status: status,
+		Result: result,
+	}
+	if err != nil {
+		response = NewErrorResponse(err)
+	}
+	response.SessionID = l.SessionID
+	if l.Segment != nil {
+		response.Segment = &SegmentResult{
+			Index:   l.Segment.Index,
+			RefText: l.Segment.RefText,
+			Offset:  l.Segment.Offset,
+		}
+	}
+
+	if !l.hideIntermediate || (status != "intermediate" && status != "segment") {
+		writeResponse(l.Conn, l.writeMu, response)
+	}
+	if l.onResponse != nil {
+		l.onResponse(response)
+	}
+}
+
+// writeResponse 向客户端发送响应，mu 用于防止多个监听器并发写入同一连接
+func writeResponse(conn *websocket.Conn, mu *sync.Mutex, response interface{}) {
+	if conn == nil {
+		log.Println("WebSocket connection is nil")
+		return
+	}
+
+	// 使用写锁防止并发写入
+	mu.Lock()
+	defer mu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetWriteDeadline(time.Time{})
+
+	if err := conn.WriteJSON(response); err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			log.Println("WebSocket closed normally")
+		} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			log.Printf("Unexpected WebSocket close: %v", err)
+		} else {
+			log.Printf("WebSocket write error: %v", err)
+		}
+	}
+}
+
+// AssessmentRequest 评测请求参数
+// 评分系数由服务端评分配置决定，客户端只能选择配置名称。
+// 指定 ItemID 时参考文本、评测模式和引擎类型取自内容库中的条目。
+type AssessmentRequest struct {
+	UserID           string  `json:"user_id"` // 服务端以请求头 X-USER-ID 为准，不一致时拒绝
+	ItemID           string  `json:"item_id"`
+	AssignmentID     string  `json:"assignment_id"`
+	RefText          string  `json:"ref_text" validate:"required"`
+	ServerEngineType string  `json:"server_engine_type" default:"16k_en"`
+	ScoringProfile   string  `json:"scoring_profile"` // 只能选择租户允许的配置，考试模式下忽略
+	Locale           string  `json:"locale"`
+	ScoreCoeff       float64 `json:"-"`
+	Tenant           string  `json:"-"`      // 由服务端按 API Key 确定
+	Region           string  `json:"region"` // 为空时使用租户或本实例所在区域
+	EvalMode         int64   `json:"eval_mode" default:"0"`
+	TextMode         int64   `json:"text_mode" default:"0"`
+	IsSaveAudioFile  bool    `json:"is_save_audio_file" default:"false"`
+	SampleRate       int     `json:"sampleRate" default:"16000"`
+	BitRate          int     `json:"bitRate" default:"16"`
+}
+
+// AudioFormat 客户端声明的音频格式
+func (req *AssessmentRequest) AudioFormat() audio.Format {
+	f := audio.DefaultFormat
+	if req.SampleRate > 0 {
+		f.SampleRate = req.SampleRate
+	}
+	if req.BitRate > 0 {
+		f.BitDepth = req.BitRate
+	}
+	return f
+}
+
+// FillDefault 按 `default` 标签为未设置的参数填充默认值
+func (req *AssessmentRequest) FillDefault() {
+	fillDefault(req)
+}
+
+// Validator 校验评测参数，返回的错误为 errno.Err
+func (req *AssessmentRequest) Validator() error {
+	if err := checkRequired(req); err != nil {
+		return err
+	}
+
+	language, ok := EngineLanguage(req.ServerEngineType)
+	if !ok {
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"server_engine_type %q is not supported.", req.ServerEngineType))
+	}
+
+	mode, ok := LookupMode(req.EvalMode, language)
+	if !ok {
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"eval_mode %d is not supported by server_engine_type %s.", req.EvalMode, req.ServerEngineType))
+	}
+
+	if req.TextMode < MinTextMode || req.TextMode > MaxTextMode {
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"text_mode must be between %d and %d.", MinTextMode, MaxTextMode))
+	}
+
+	if req.ScoreCoeff < MinScoreCoeff || req.ScoreCoeff > MaxScoreCoeff {
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"score_coeff must be between %.1f and %.1f.", MinScoreCoeff, MaxScoreCoeff))
+	}
+
+	return CheckWordLimit(mode, req.RefText)
+}
+
+type AssessmentResponse struct {
+	Status    string         `json:"status"`
+	SessionID string         `json:"session_id,omitempty"`
+	Result    *SOEResult     `json:"result,omitempty"`
+	Segment   *SegmentResult `json:"segment,omitempty"`
+	Code      string         `json:"code,omitempty"`
+	Engine    string         `json:"engine,omitempty"` // status 为 fallback 时切换到的引擎
+	Error     string         `json:"error,omitempty"`
+	Retryable *bool          `json:"retryable,omitempty"` // 错误响应中表示客户端是否可以重新发起评测
+}
+
+// NewErrorResponse 构造错误响应，SDK 和上游的错误转换为对应的 errno.Err，携带错误码和是否可重试
+func NewErrorResponse(err error) AssessmentResponse {
+	e := upstream.Error(err)
+	if e.RawErr != nil {
+		log.Printf("Error response: %s: %v", e.Code, e.RawErr)
+	}
+
+	return AssessmentResponse{
+		Status:    "error",
+		Code:      e.Code,
+		Error:     e.Message,
+		Retryable: &e.Retryable,
+	}
+}
+
+type SOEResult struct {
+	OverallScore   float64          `json:"overall_score,omitempty"`
+	Words          []soe.WordRsp    `json:"words,omitempty"`
+	PronAccuracy   float64          `json:"pron_accuracy,omitempty"`
+	PronFluency    float64          `json:"pron_fluency,omitempty"`
+	PronCompletion float64          `json:"pron_completion,omitempty"`
+	Segments       []*SegmentResult `json:"segments,omitempty"`
+	Diagnostics    *Diagnostics     `json:"diagnostics,omitempty"`
+	Score          float64          `json:"score,omitempty"`   // 按评分配置加权后的最终得分
+	Level          string           `json:"level,omitempty"`   // 最终得分对应的等级
+	Profile        string           `json:"profile,omitempty"` // 使用的评分配置
+	Feedback       []string         `json:"feedback,omitempty"`
+	Engine         string           `json:"engine,omitempty"` // 产生结果的引擎
+
+	// Suspicious 录音疑似回放或与他人录音重复，最终结果发送后才检测，只保存在评测记录中
+	Suspicious        bool     `json:"suspicious,omitempty"`
+	SuspiciousReasons []string `json:"suspicious_reasons,omitempty"`
+}
@@ -0,0 +1,565 @@
+package speech
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TokenMapping 参考文本中一个原始单词与规范化后单词的对应关系
+type TokenMapping struct {
+	Original string   `json:"original"`
+	Words    []string `json:"words"`
+}
+
+// NormalizedText 规范化后的参考文本
+type NormalizedText struct {
+	Original string         `json:"original"`
+	Text     string         `json:"text"`
+	Language string         `json:"language"`
+	Tokens   []TokenMapping `json:"tokens"`
+}
+
+var (
+	// 引号、破折号等标点统一为 ASCII 形式
+	punctReplacer = strings.NewReplacer(
+		"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'", "`", "'",
+		"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`, "«", `"`, "»", `"`,
+		"–", "-", "‐", "-", "‑", "-", "—", " ", "―", " ",
+		"…", "...",
+	)
+
+	// 常见缩写展开（键为小写）
+	abbreviations = map[string]string{
+		"dr.":     "doctor",
+		"mr.":     "mister",
+		"mrs.":    "missus",
+		"ms.":     "miss",
+		"prof.":   "professor",
+		"st.":     "street",
+		"mt.":     "mount",
+		"ave.":    "avenue",
+		"jr.":     "junior",
+		"sr.":     "senior",
+		"dept.":   "department",
+		"approx.": "approximately",
+		"etc.":    "et cetera",
+		"e.g.":    "for example",
+		"i.e.":    "that is",
+		"vs.":     "versus",
+		"no.":     "number",
+		"&":       "and",
+	}
+
+	// 保留在规范化文本中的句读标点
+	keptPunct = ".,!?;:'\"。，！？；：、"
+)
+
+// Normalize 规范化参考文本
+// 依次进行 Unicode 规范化（NFKC）、标点清理（智能引号、破折号、emoji）、缩写与数字展开，
+// 并记录每个原始单词对应的规范化单词，用于把评测结果映射回老师输入的文本。
+func Normalize(text, language string) *NormalizedText {
+	n := &NormalizedText{Original: text, Language: language}
+
+	var out []string
+	for _, raw := range strings.Fields(text) {
+		if language == "zh" {
+			out = append(out, n.normalizeZh(raw))
+		} else {
+			out = append(out, n.normalizeEn(raw))
+		}
+	}
+
+	if language == "zh" {
+		n.Text = strings.Join(out, "")
+	} else {
+		n.Text = strings.Join(strings.Fields(strings.Join(out, " ")), " ")
+	}
+
+	return n
+}
+
+// Changed 规范化是否改变了参考文本
+func (n *NormalizedText) Changed() bool {
+	return n.Text != n.Original
+}
+
+// normalizeEn 处理一个以空白分隔的英文原始单词，返回规范化后的文本片段
+func (n *NormalizedText) normalizeEn(raw string) string {
+	cleaned := cleanup(raw)
+
+	var parts []string
+	for _, field := range strings.Fields(cleaned) {
+		lead, core, trail := splitPunct(field)
+
+		var words []string
+		if expansion, ok := abbreviations[strings.ToLower(core+".")]; ok && strings.HasPrefix(trail, ".") {
+			// 缩写的句点属于缩写本身
+			words, core, trail = strings.Fields(matchCase(expansion, core)), core+".", trail[1:]
+		} else if expansion, ok := abbreviations[strings.ToLower(core)]; ok {
+			words = strings.Fields(matchCase(expansion, core))
+		} else if spoken, ok := expandNumberEn(core); ok {
+			words = strings.Fields(spoken)
+		} else if len(core) > 0 {
+			words = []string{core}
+		}
+
+		if len(words) == 0 {
+			continue
+		}
+
+		n.Tokens = append(n.Tokens, TokenMapping{
+			Original: originalCore(raw, core),
+			Words:    words,
+		})
+		parts = append(parts, keepPunct(lead)+strings.Join(words, " ")+keepPunct(trail))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// normalizeZh 处理一段中文文本，汉字逐字对应，数字展开为中文数字
+func (n *NormalizedText) normalizeZh(raw string) string {
+	cleaned := []rune(cleanup(raw))
+
+	var b strings.Builder
+	for i := 0; i < len(cleaned); i++ {
+		r := cleaned[i]
+		switch {
+		case unicode.IsDigit(r):
+			// 小数点最多一个且后面须是数字，日期、版本号等在第二个小数点处截断
+			j, dot := i, false
+			for j < len(cleaned) {
+				c := cleaned[j]
+				if c == '.' {
+					if dot || j+1 >= len(cleaned) || !unicode.IsDigit(cleaned[j+1]) {
+						break
+					}
+					dot = true
+				} else if c == '%' {
+					j++
+					break
+				} else if !unicode.IsDigit(c) {
+					break
+				}
+				j++
+			}
+			number := string(cleaned[i:j])
+			spoken, ok := expandNumberZh(number)
+			if !ok {
+				spoken = number
+			}
+			n.Tokens = append(n.Tokens, TokenMapping{Original: number, Words: splitRunes(spoken)})
+			b.WriteString(spoken)
+			i = j - 1
+		case unicode.Is(unicode.Han, r) || unicode.IsLetter(r):
+			n.Tokens = append(n.Tokens, TokenMapping{Original: string(r), Words: []string{string(r)}})
+			b.WriteRune(r)
+		case strings.ContainsRune(keptPunct, r):
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// Remap 将评测结果中的单词映射回原始单词
+// 一个原始单词展开为多个单词时（如 21 → twenty-one、Dr. → doctor），合并为一个结果。
+func (n *NormalizedText) Remap(words []soe.WordRsp) []soe.WordRsp {
+	if n == nil || !n.Changed() || len(words) == 0 {
+		return words
+	}
+
+	// 规范化单词按连字符（英文）或单字（中文）拆分，记录所属的原始单词
+	var pieces []int
+	for i, token := range n.Tokens {
+		for _, w := range token.Words {
+			for range n.pieces(w) {
+				pieces = append(pieces, i)
+			}
+		}
+	}
+
+	var (
+		remapped []soe.WordRsp
+		group    []soe.WordRsp
+		groupTok = -1
+		next     int
+	)
+	flush := func() {
+		if len(group) > 0 {
+			remapped = append(remapped, mergeWords(group, n.Tokens[groupTok].Original))
+			group, groupTok = nil, -1
+		}
+	}
+
+	for _, w := range words {
+		// 1: 多读的单词没有对应的参考单词，原样保留
+		if w.Tag == 1 || next >= len(pieces) {
+			flush()
+			remapped = append(remapped, w)
+			continue
+		}
+
+		token := pieces[next]
+		next += len(n.pieces(w.ReferenceWord))
+		if token != groupTok {
+			flush()
+			groupTok = token
+		}
+		group = append(group, w)
+	}
+	flush()
+
+	return remapped
+}
+
+// pieces 对齐时的最小单位
+func (n *NormalizedText) pieces(word string) []string {
+	if n.Language == "zh" {
+		return splitRunes(word)
+	}
+	parts := strings.FieldsFunc(word, func(r rune) bool { return r == '-' })
+	if len(parts) == 0 {
+		return []string{word}
+	}
+	return parts
+}
+
+// mergeWords 合并同一原始单词对应的多个结果，得分取平均，匹配标记取最差
+func mergeWords(group []soe.WordRsp, original string) soe.WordRsp {
+	merged := group[0]
+	merged.ReferenceWord = original
+	if len(group) == 1 {
+		return merged
+	}
+
+	var (
+		words    []string
+		accuracy float64
+		fluency  float64
+		omitted  int
+		mismatch bool
+	)
+	merged.PhoneInfo = nil
+	for _, w := range group {
+		words = append(words, w.Word)
+		accuracy += w.PronAccuracy
+		fluency += w.PronFluency
+		merged.PhoneInfo = append(merged.PhoneInfo, w.PhoneInfo...)
+		if w.Tag == 2 {
+			omitted++
+		} else if w.Tag != 0 {
+			mismatch = true
+		}
+	}
+
+	merged.Word = strings.Join(words, " ")
+	merged.PronAccuracy = accuracy / float64(len(group))
+	merged.PronFluency = fluency / float64(len(group))
+	merged.Metm = group[len(group)-1].Metm
+
+	switch {
+	case omitted == len(group):
+		merged.Tag = 2 // 缺读
+	case omitted > 0 || mismatch:
+		merged.Tag = 3 // 错读
+	default:
+		merged.Tag = 0
+	}
+
+	return merged
+}
+
+// cleanup Unicode 规范化并清理标点、emoji 等符号
+func cleanup(s string) string {
+	s = punctReplacer.Replace(norm.NFKC.String(s))
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0xFE00 && r <= 0xFE0F: // 变体选择符
+			return -1
+		case unicode.In(r, unicode.So, unicode.Sk, unicode.Cf, unicode.Co, unicode.Cs):
+			return -1
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// splitPunct 拆分单词首尾的标点
+func splitPunct(s string) (lead, core, trail string) {
+	isPunct := func(r rune) bool { return !isWordRune(r) && r != '&' && r != '%' && r != '$' }
+
+	core = strings.TrimLeftFunc(s, isPunct)
+	lead = s[:len(s)-len(core)]
+	trimmed := strings.TrimRightFunc(core, isPunct)
+	trail = core[len(trimmed):]
+	return lead, trimmed, trail
+}
+
+// originalCore 从原始单词中取出与 core 对应的部分（去掉首尾标点），用于结果展示
+func originalCore(raw, core string) string {
+	if strings.Contains(raw, core) {
+		return core
+	}
+	_, c, _ := splitPunct(raw)
+	if len(c) == 0 {
+		return raw
+	}
+	return c
+}
+
+// keepPunct 只保留句读标点
+func keepPunct(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(keptPunct, r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// matchCase 原单词首字母大写时，展开结果也首字母大写
+func matchCase(expansion, core string) string {
+	if r := []rune(core); len(r) > 0 && unicode.IsUpper(r[0]) {
+		e := []rune(expansion)
+		e[0] = unicode.ToUpper(e[0])
+		return string(e)
+	}
+	return expansion
+}
+
+func splitRunes(s string) []string {
+	var out []string
+	for _, r := range s {
+		out = append(out, string(r))
+	}
+	return out
+}
+
+var (
+	enOnes = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	enTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	enScales = []string{"", "thousand", "million", "billion"}
+
+	enOrdinals = map[string]string{
+		"one": "first", "two": "second", "three": "third", "five": "fifth",
+		"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+	}
+)
+
+// expandNumberEn 展开英文数字：整数、千分位、小数、序数（21st）、百分数、美元
+func expandNumberEn(s string) (string, bool) {
+	if len(s) == 0 || strings.IndexFunc(s, unicode.IsDigit) < 0 {
+		return "", false
+	}
+
+	var suffix string
+	if strings.HasPrefix(s, "$") {
+		s, suffix = s[1:], " dollars"
+	}
+	if strings.HasSuffix(s, "%") {
+		s, suffix = s[:len(s)-1], " percent"
+	}
+
+	lower := strings.ToLower(s)
+	for _, ord := range []string{"st", "nd", "rd", "th"} {
+		if strings.HasSuffix(lower, ord) {
+			n, err := strconv.ParseInt(s[:len(s)-2], 10, 64)
+			if err != nil {
+				return "", false
+			}
+			return ordinalEn(cardinalEn(n)) + suffix, true
+		}
+	}
+
+	s = strings.ReplaceAll(s, ",", "")
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || n >= 1e12 {
+		return "", false
+	}
+
+	spoken := cardinalEn(n)
+	if hasFrac {
+		if !asciiDigits(fracPart) {
+			return "", false
+		}
+		spoken += " point"
+		for _, d := range fracPart {
+			spoken += " " + enOnes[d-'0']
+		}
+	}
+	if suffix == " dollars" && n == 1 && !hasFrac {
+		suffix = " dollar"
+	}
+
+	return spoken + suffix, true
+}
+
+// cardinalEn 基数词，21 → twenty-one
+func cardinalEn(n int64) string {
+	if n < 0 {
+		return "minus " + cardinalEn(-n)
+	}
+	if n < 20 {
+		return enOnes[n]
+	}
+	if n < 100 {
+		if n%10 == 0 {
+			return enTens[n/10]
+		}
+		return enTens[n/10] + "-" + enOnes[n%10]
+	}
+	if n < 1000 {
+		if n%100 == 0 {
+			return enOnes[n/100] + " hundred"
+		}
+		return enOnes[n/100] + " hundred " + cardinalEn(n%100)
+	}
+
+	var parts []string
+	for scale := len(enScales) - 1; scale >= 0; scale-- {
+		unit := int64(1)
+		for i := 0; i < scale; i++ {
+			unit *= 1000
+		}
+		if n >= unit {
+			chunk := n / unit
+			n %= unit
+			part := cardinalEn(chunk)
+			if scale > 0 {
+				part += " " + enScales[scale]
+			}
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ordinalEn 将基数词的最后一个词转换为序数词
+func ordinalEn(cardinal string) string {
+	i := strings.LastIndexAny(cardinal, " -")
+	head, last := cardinal[:i+1], cardinal[i+1:]
+
+	if ord, ok := enOrdinals[last]; ok {
+		return head + ord
+	}
+	if strings.HasSuffix(last, "y") {
+		return head + strings.TrimSuffix(last, "y") + "ieth"
+	}
+	return head + last + "th"
+}
+
+var (
+	zhDigits = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	zhUnits  = []string{"", "十", "百", "千"}
+	zhScales = []string{"", "万", "亿"}
+)
+
+// expandNumberZh 展开中文数字：整数、小数、百分数
+func expandNumberZh(s string) (string, bool) {
+	var prefix string
+	if strings.HasSuffix(s, "%") {
+		s, prefix = s[:len(s)-1], "百分之"
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || n >= 1e12 {
+		return "", false
+	}
+
+	spoken := prefix + cardinalZh(n)
+	if hasFrac {
+		if !asciiDigits(fracPart) {
+			return "", false
+		}
+		spoken += "点"
+		for _, d := range fracPart {
+			spoken += zhDigits[d-'0']
+		}
+	}
+
+	return spoken, true
+}
+
+// asciiDigits 是否为非空的 ASCII 数字串，全角等其他数字不展开
+func asciiDigits(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// cardinalZh 中文基数词，105 → 一百零五，12 → 十二
+func cardinalZh(n int64) string {
+	if n == 0 {
+		return zhDigits[0]
+	}
+
+	var (
+		groups []int64
+		out    string
+	)
+	for n > 0 {
+		groups = append(groups, n%10000)
+		n /= 10000
+	}
+
+	needZero := false
+	for i := len(groups) - 1; i >= 0; i-- {
+		g := groups[i]
+		if g == 0 {
+			needZero = true
+			continue
+		}
+		if needZero || (len(out) > 0 && g < 1000) {
+			out += zhDigits[0]
+		}
+		out += groupZh(g) + zhScales[i]
+		needZero = false
+	}
+
+	// 10-19 读作 十、十一……
+	if strings.HasPrefix(out, "一十") {
+		out = strings.TrimPrefix(out, "一")
+	}
+	return out
+}
+
+// groupZh 四位以内的中文数字
+func groupZh(n int64) string {
+	var (
+		out  string
+		zero bool
+	)
+	for pos := 3; pos >= 0; pos-- {
+		unit := int64(1)
+		for i := 0; i < pos; i++ {
+			unit *= 10
+		}
+		d := (n / unit) % 10
+		if d == 0 {
+			zero = len(out) > 0
+			continue
+		}
+		if zero {
+			out += zhDigits[0]
+			zero = false
+		}
+		out += zhDigits[d] + zhUnits[pos]
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package speech
+
+import "testing"
+
+func TestNormalizeNumbers(t *testing.T) {
+	tests := []struct {
+		text     string
+		language string
+		want     string
+	}{
+		{"今天是2024.10.19", "zh", "今天是二千零二十四点一零.十九"},
+		{"服务器地址是192.168.1.1", "zh", "服务器地址是一百九十二点一六八.一点一"},
+		{"第1..2页", "zh", "第一..二页"},
+		{"价格是３.５元", "zh", "价格是三点五元"},
+		{"价格是3.٥元", "zh", "价格是3.٥元"},
+		{"增长了3.5%", "zh", "增长了百分之三点五"},
+		{"I was born on 2024.10.19", "en", "I was born on 2024.10.19"},
+		{"Connect to 192.168.1.1", "en", "Connect to 192.168.1.1"},
+		{"Read pages 1..2", "en", "Read pages 1..2"},
+		{"It costs ３.５ dollars", "en", "It costs three point five dollars"},
+		{"It costs 3.٥ dollars", "en", "It costs 3.٥ dollars"},
+		{"It costs 3.5 dollars", "en", "It costs three point five dollars"},
+	}
+
+	for _, tt := range tests {
+		got := Normalize(tt.text, tt.language)
+		if got.Text != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, want %q", tt.text, tt.language, got.Text, tt.want)
+		}
+	}
+}
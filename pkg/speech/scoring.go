@@ -0,0 +1,152 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"lingolift/errno"
+)
+
+// DefaultScoringProfile 未配置或客户端未指定时使用的评分配置名称
+const DefaultScoringProfile = "default"
+
+// ScoreBand 分数段，MinScore 为该段的最低分（含）
+type ScoreBand struct {
+	Label       string  `yaml:"label" json:"label"`
+	MinScore    float64 `yaml:"min_score" json:"min_score"`
+	Description string  `yaml:"description" json:"description,omitempty"`
+}
+
+// ScoringProfile 评分配置
+// 权重均为 0 时直接使用引擎给出的建议总分。
+type ScoringProfile struct {
+	ScoreCoeff       float64     `yaml:"score_coeff"`
+	AccuracyWeight   float64     `yaml:"accuracy_weight"`
+	FluencyWeight    float64     `yaml:"fluency_weight"`
+	CompletionWeight float64     `yaml:"completion_weight"`
+	Bands            []ScoreBand `yaml:"bands"` // 为空时使用全局分数段
+}
+
+// ScoringConfig 服务端评分策略
+type ScoringConfig struct {
+	DefaultProfile string                     `yaml:"default_profile"`
+	Profiles       map[string]*ScoringProfile `yaml:"profiles"`
+	Bands          []ScoreBand                `yaml:"bands"`
+}
+
+// DefaultBands CEFR 等级分数段
+var DefaultBands = []ScoreBand{
+	{Label: "A1", MinScore: 0, Description: "Beginner"},
+	{Label: "A2", MinScore: 40, Description: "Elementary"},
+	{Label: "B1", MinScore: 55, Description: "Intermediate"},
+	{Label: "B2", MinScore: 70, Description: "Upper intermediate"},
+	{Label: "C1", MinScore: 82, Description: "Advanced"},
+	{Label: "C2", MinScore: 92, Description: "Proficient"},
+}
+
+// Check 检查评分配置并填充默认值
+func (c *ScoringConfig) Check() error {
+	if c.Profiles == nil {
+		c.Profiles = map[string]*ScoringProfile{}
+	}
+	if _, ok := c.Profiles[DefaultScoringProfile]; !ok {
+		c.Profiles[DefaultScoringProfile] = &ScoringProfile{ScoreCoeff: 1.0}
+	}
+	if len(c.DefaultProfile) == 0 {
+		c.DefaultProfile = DefaultScoringProfile
+	}
+	if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+		return fmt.Errorf("scoring default_profile %q is not defined", c.DefaultProfile)
+	}
+	if len(c.Bands) == 0 {
+		c.Bands = DefaultBands
+	}
+
+	for name, p := range c.Profiles {
+		if p.ScoreCoeff == 0 {
+			p.ScoreCoeff = 1.0
+		}
+		if p.ScoreCoeff < MinScoreCoeff || p.ScoreCoeff > MaxScoreCoeff {
+			return fmt.Errorf("scoring profile %q: score_coeff must be between %.1f and %.1f", name, MinScoreCoeff, MaxScoreCoeff)
+		}
+		if p.AccuracyWeight < 0 || p.FluencyWeight < 0 || p.CompletionWeight < 0 {
+			return fmt.Errorf("scoring profile %q: weights must not be negative", name)
+		}
+		sortBands(p.Bands)
+	}
+	sortBands(c.Bands)
+
+	return nil
+}
+
+// Lookup 按名称查找评分配置，名称为空时使用默认配置
+func (c *ScoringConfig) Lookup(name string) (*Scorer, error) {
+	if len(name) == 0 {
+		name = c.DefaultProfile
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf("scoring_profile %q is not supported.", name))
+	}
+
+	bands := profile.Bands
+	if len(bands) == 0 {
+		bands = c.Bands
+	}
+
+	return &Scorer{Name: name, Profile: profile, Bands: bands}, nil
+}
+
+// Scorer 按评分配置计算最终得分和等级
+type Scorer struct {
+	Name    string
+	Profile *ScoringProfile
+	Bands   []ScoreBand
+}
+
+// Apply 计算最终得分并映射到分数段
+func (s *Scorer) Apply(result *SOEResult) {
+	if s == nil || result == nil {
+		return
+	}
+
+	result.Profile = s.Name
+	result.Score = s.score(result)
+	if band := s.band(result.Score); band != nil {
+		result.Level = band.Label
+	}
+}
+
+// score 按权重加权准确度、流畅度、完整度（流畅度、完整度为 0-1，换算为百分制）
+func (s *Scorer) score(result *SOEResult) float64 {
+	p := s.Profile
+	total := p.AccuracyWeight + p.FluencyWeight + p.CompletionWeight
+	if total <= 0 {
+		return math.Round(result.OverallScore*100) / 100
+	}
+
+	score := (result.PronAccuracy*p.AccuracyWeight +
+		result.PronFluency*100*p.FluencyWeight +
+		result.PronCompletion*100*p.CompletionWeight) / total
+
+	return math.Round(math.Max(0, math.Min(100, score))*100) / 100
+}
+
+// band 返回分数所在的分数段
+func (s *Scorer) band(score float64) *ScoreBand {
+	var matched *ScoreBand
+	for i := range s.Bands {
+		if score >= s.Bands[i].MinScore {
+			matched = &s.Bands[i]
+		}
+	}
+	return matched
+}
+
+func sortBands(bands []ScoreBand) {
+	sort.Slice(bands, func(i, j int) bool {
+		return bands[i].MinScore < bands[j].MinScore
+	})
+}
@@ -0,0 +1,89 @@
+package speech
+
+import (
+	"testing"
+
+	"lingolift/errno"
+)
+
+func TestScoringConfigCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ScoringConfig
+		wantErr bool
+	}{
+		{"empty", ScoringConfig{}, false},
+		{"custom default", ScoringConfig{DefaultProfile: "exam", Profiles: map[string]*ScoringProfile{"exam": {}}}, false},
+		{"undefined default", ScoringConfig{DefaultProfile: "exam"}, true},
+		{"score_coeff too large", ScoringConfig{Profiles: map[string]*ScoringProfile{"exam": {ScoreCoeff: 5}}}, true},
+		{"negative weight", ScoringConfig{Profiles: map[string]*ScoringProfile{"exam": {FluencyWeight: -1}}}, true},
+	}
+
+	for _, tt := range tests {
+		c := tt.config
+		err := c.Check()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Check() = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if _, ok := c.Profiles[DefaultScoringProfile]; !ok {
+			t.Errorf("%s: Check() did not add the %q profile", tt.name, DefaultScoringProfile)
+		}
+		if len(c.Bands) != len(DefaultBands) {
+			t.Errorf("%s: len(Bands) = %d, want the default bands", tt.name, len(c.Bands))
+		}
+		for name, p := range c.Profiles {
+			if p.ScoreCoeff != 1.0 {
+				t.Errorf("%s: profile %q ScoreCoeff = %v, want 1.0", tt.name, name, p.ScoreCoeff)
+			}
+		}
+	}
+}
+
+func TestScorerApply(t *testing.T) {
+	c := ScoringConfig{Profiles: map[string]*ScoringProfile{
+		"speaking": {AccuracyWeight: 1, FluencyWeight: 1, Bands: []ScoreBand{
+			{Label: "pass", MinScore: 60},
+			{Label: "fail", MinScore: 0},
+		}},
+	}}
+	if err := c.Check(); err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+
+	tests := []struct {
+		profile string
+		result  SOEResult
+		name    string
+		score   float64
+		level   string
+	}{
+		{"", SOEResult{OverallScore: 85.456}, "default", 85.46, "C1"},
+		{"default", SOEResult{OverallScore: 30}, "default", 30, "A1"},
+		{"default", SOEResult{OverallScore: 92}, "default", 92, "C2"},
+		{"speaking", SOEResult{OverallScore: 10, PronAccuracy: 80, PronFluency: 0.5}, "speaking", 65, "pass"},
+		{"speaking", SOEResult{PronAccuracy: 40, PronFluency: 0.5}, "speaking", 45, "fail"},
+		{"speaking", SOEResult{PronAccuracy: 150, PronFluency: 1}, "speaking", 100, "pass"},
+	}
+
+	for _, tt := range tests {
+		scorer, err := c.Lookup(tt.profile)
+		if err != nil {
+			t.Fatalf("Lookup(%q) = %v", tt.profile, err)
+		}
+		result := tt.result
+		scorer.Apply(&result)
+		if result.Profile != tt.name || result.Score != tt.score || result.Level != tt.level {
+			t.Errorf("Apply(%q, %+v) = %s %v %s, want %s %v %s", tt.profile, tt.result,
+				result.Profile, result.Score, result.Level, tt.name, tt.score, tt.level)
+		}
+	}
+
+	_, err := c.Lookup("missing")
+	if e, ok := err.(errno.Err); !ok || e.Code != errno.ErrInvalidParameterValue.Code {
+		t.Errorf("Lookup(missing) = %v, want %s", err, errno.ErrInvalidParameterValue.Code)
+	}
+}
@@ -0,0 +1,212 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+// SegmentResult 分段评测中单段的评测结果
+type SegmentResult struct {
+	Index   int        `json:"index"`
+	RefText string     `json:"ref_text"`
+	Offset  int64      `json:"offset"` // 该段在整段音频中的起始时间（毫秒）
+	Result  *SOEResult `json:"result,omitempty"`
+}
+
+// SentenceMode 返回语言对应的句子模式
+func SentenceMode(language string) ModeType {
+	if language == "zh" {
+		return ChnSentence
+	}
+	return EngSentence
+}
+
+// SplitText 将超出字数限制的段落拆分为句子大小的多段
+// 不可拆分的模式或未超出限制时原样返回。
+func SplitText(mode ModeType, text string) []string {
+	rule := modeRules[mode]
+	if !rule.splittable || CountWords(text, rule.language) <= rule.maxWords {
+		return []string{text}
+	}
+
+	language := rule.language
+	limit := modeRules[SentenceMode(language)].maxWords
+
+	var (
+		segments []string
+		current  []string
+		count    int
+	)
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, joinText(current, language))
+			current, count = nil, 0
+		}
+	}
+
+	// 尽量把完整的句子合并到同一段，单句超长时再按子句、单词拆分
+	for _, sentence := range splitOn(text, ".!?;。！？；") {
+		for _, part := range splitLong(sentence, language, limit) {
+			n := CountWords(part, language)
+			if count > 0 && count+n > limit {
+				flush()
+			}
+			current = append(current, part)
+			count += n
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// Aggregate 汇总各段结果，总分按各段字数加权
+// 未评测到的段按 0 分计入，时间戳换算为整段音频中的时间。
+func Aggregate(language string, segments []*SegmentResult) *SOEResult {
+	result := &SOEResult{Segments: segments}
+
+	var total float64
+	for _, seg := range segments {
+		weight := float64(CountWords(seg.RefText, language))
+		total += weight
+
+		if seg.Result == nil {
+			continue
+		}
+
+		result.OverallScore += seg.Result.OverallScore * weight
+		result.PronAccuracy += seg.Result.PronAccuracy * weight
+		result.PronFluency += seg.Result.PronFluency * weight
+		result.PronCompletion += seg.Result.PronCompletion * weight
+
+		for _, w := range seg.Result.Words {
+			w.Mbtm += seg.Offset
+			w.Metm += seg.Offset
+			result.Words = append(result.Words, w)
+		}
+	}
+
+	if total > 0 {
+		result.OverallScore /= total
+		result.PronAccuracy /= total
+		result.PronFluency /= total
+		result.PronCompletion /= total
+	}
+
+	return result
+}
+
+// coversLastWord 中间结果是否已读到该段的最后一个单词
+func coversLastWord(result *SOEResult, text, language string) bool {
+	if result == nil || len(result.Words) == 0 {
+		return false
+	}
+
+	var last soe.WordRsp
+	found := false
+	for i := len(result.Words) - 1; i >= 0; i-- {
+		if result.Words[i].Tag != 2 { // 2: 缺读
+			last, found = result.Words[i], true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	words := tokenize(text, language)
+	if len(words) == 0 {
+		return false
+	}
+	target := words[len(words)-1]
+	return strings.EqualFold(trimWord(last.ReferenceWord), target)
+}
+
+// splitLong 单句超出限制时按子句拆分，子句仍超出时按单词/汉字硬拆分
+func splitLong(sentence, language string, limit int) []string {
+	if CountWords(sentence, language) <= limit {
+		return []string{sentence}
+	}
+
+	var parts []string
+	for _, clause := range splitOn(sentence, ",:，、：") {
+		if CountWords(clause, language) <= limit {
+			parts = append(parts, clause)
+			continue
+		}
+
+		tokens := tokenize(clause, language)
+		for i := 0; i < len(tokens); i += limit {
+			end := i + limit
+			if end > len(tokens) {
+				end = len(tokens)
+			}
+			parts = append(parts, joinText(tokens[i:end], language))
+		}
+	}
+	return parts
+}
+
+// splitOn 在分隔符之后切分文本，分隔符保留在前一部分末尾
+func splitOn(text, seps string) []string {
+	var (
+		parts []string
+		start int
+	)
+	runes := []rune(text)
+	for i, r := range runes {
+		if !strings.ContainsRune(seps, r) {
+			continue
+		}
+		// 英文标点后需跟空白才视为分隔，避免拆开 3.14、e.g. 等写法
+		if r < unicode.MaxASCII && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		if part := strings.TrimSpace(string(runes[start : i+1])); len(part) > 0 {
+			parts = append(parts, part)
+		}
+		start = i + 1
+	}
+	if part := strings.TrimSpace(string(runes[start:])); len(part) > 0 {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// tokenize 英文按空白拆分单词，中文按汉字拆分
+func tokenize(text, language string) []string {
+	if language != "zh" {
+		var words []string
+		for _, field := range strings.Fields(text) {
+			if w := trimWord(field); len(w) > 0 {
+				words = append(words, w)
+			}
+		}
+		return words
+	}
+
+	var chars []string
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			chars = append(chars, string(r))
+		}
+	}
+	return chars
+}
+
+// joinText 英文以空格连接，中文直接连接
+func joinText(parts []string, language string) string {
+	if language == "zh" {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, " ")
+}
+
+// trimWord 去掉单词首尾的标点
+func trimWord(word string) string {
+	return strings.TrimFunc(word, func(r rune) bool {
+		return !isWordRune(r) && r != '\''
+	})
+}
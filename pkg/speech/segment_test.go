@@ -0,0 +1,113 @@
+package speech
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-speech-sdk-go/soe"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ModeType
+		text     string
+		segments int
+	}{
+		{"not splittable", EngSentence, strings.Repeat("word ", 40), 1},
+		{"within limit", EngParagraph, strings.Repeat("I like apples. ", 40), 1},
+		{"sentences", EngParagraph, strings.Repeat("I like apples. ", 41), 5},
+		{"clauses", EngParagraph, strings.Repeat("one two three four five six seven eight nine ten, ", 13) + "end.", 5},
+		{"no punctuation", EngParagraph, strings.Repeat("word ", 130), 5},
+		{"decimal point", EngParagraph, strings.Repeat("pi is 3.14 or so ", 25), 5},
+		{"chinese sentences", ChnParagraph, strings.Repeat("今天天气很好。", 21), 5},
+		{"chinese no punctuation", ChnParagraph, strings.Repeat("好", 121), 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language := modeRules[tt.mode].language
+			limit := modeRules[SentenceMode(language)].maxWords
+
+			segments := SplitText(tt.mode, tt.text)
+			if len(segments) != tt.segments {
+				t.Fatalf("SplitText() = %d segments, want %d", len(segments), tt.segments)
+			}
+			if len(segments) == 1 {
+				if segments[0] != tt.text {
+					t.Errorf("SplitText() = %q, want the text unchanged", segments[0])
+				}
+				return
+			}
+
+			// 每段不超过句子模式的字数限制，拼接后与原文的单词一致
+			var words []string
+			for _, seg := range segments {
+				if n := CountWords(seg, language); n > limit {
+					t.Errorf("segment %q has %d words, want at most %d", seg, n, limit)
+				}
+				words = append(words, tokenize(seg, language)...)
+			}
+			if got, want := joinText(words, language), joinText(tokenize(tt.text, language), language); got != want {
+				t.Errorf("joined segments = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	segments := []*SegmentResult{
+		{Index: 0, RefText: "hello world", Offset: 0, Result: &SOEResult{
+			OverallScore: 90, PronAccuracy: 80, PronFluency: 1, PronCompletion: 1,
+			Words: []soe.WordRsp{{Word: "hello", Mbtm: 100, Metm: 400}, {Word: "world", Mbtm: 500, Metm: 900}},
+		}},
+		{Index: 1, RefText: "good morning everyone", Offset: 3000, Result: &SOEResult{
+			OverallScore: 60, PronAccuracy: 50, PronFluency: 0.5, PronCompletion: 1,
+			Words: []soe.WordRsp{{Word: "good", Mbtm: 100, Metm: 300}},
+		}},
+		{Index: 2, RefText: "thank you very much", Offset: 6000}, // 未评测
+	}
+
+	result := Aggregate("en", segments)
+
+	scores := []struct {
+		name      string
+		got, want float64
+	}{
+		{"OverallScore", result.OverallScore, (90*2 + 60*3) / 9.0},
+		{"PronAccuracy", result.PronAccuracy, (80*2 + 50*3) / 9.0},
+		{"PronFluency", result.PronFluency, (1*2 + 0.5*3) / 9.0},
+		{"PronCompletion", result.PronCompletion, (1*2 + 1*3) / 9.0},
+	}
+	for _, s := range scores {
+		if math.Abs(s.got-s.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", s.name, s.got, s.want)
+		}
+	}
+
+	words := []struct {
+		word       string
+		mbtm, metm int64
+	}{
+		{"hello", 100, 400},
+		{"world", 500, 900},
+		{"good", 3100, 3300},
+	}
+	if len(result.Words) != len(words) {
+		t.Fatalf("len(Words) = %d, want %d", len(result.Words), len(words))
+	}
+	for i, w := range words {
+		got := result.Words[i]
+		if got.Word != w.word || got.Mbtm != w.mbtm || got.Metm != w.metm {
+			t.Errorf("Words[%d] = %s %d-%d, want %s %d-%d", i, got.Word, got.Mbtm, got.Metm, w.word, w.mbtm, w.metm)
+		}
+	}
+	// 汇总不修改各段自身的时间戳
+	if segments[1].Result.Words[0].Mbtm != 100 {
+		t.Errorf("segment word Mbtm = %d, want 100", segments[1].Result.Words[0].Mbtm)
+	}
+	if len(result.Segments) != len(segments) {
+		t.Errorf("len(Segments) = %d, want %d", len(result.Segments), len(segments))
+	}
+}
@@ -0,0 +1,503 @@
+package speech
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"lingolift/errno"
+	"lingolift/pkg/audio"
+	"lingolift/pkg/store"
+	"lingolift/pkg/upstream"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 未能通过中间结果判断是否读完时，按语速估算每段所需的语音时长（秒/词、秒/字）
+	enSecondsPerWord = 0.6
+	zhSecondsPerChar = 0.4
+)
+
+// resultTimeout 客户端音频结束后等待评测结果的最长时间
+var resultTimeout = 60 * time.Second
+
+// Recognizer 识别器，由 soe.SpeechRecognizer 实现
+type Recognizer interface {
+	Start() error
+	Write(data []byte) error
+	Stop() error
+}
+
+// RecognizerFactory 根据评测参数创建识别器
+type RecognizerFactory func(req *AssessmentRequest, listener *StreamListener) (Recognizer, error)
+
+// Session 一次评测会话
+// 参考文本超出段落字数限制时拆分为多段，在句间停顿处依次切换识别器评测同一路音频，最后汇总结果。
+// 识别器失败时切换到下一个引擎，重放失败段已接收的音频。
+type Session struct {
+	ID        string
+	Conn      *websocket.Conn
+	Request   *AssessmentRequest
+	ErrorChan chan error
+	Complete  chan struct{}
+	Scorer    *Scorer
+	Feedback  *Feedback
+
+	// Exam 考试模式：不向学生发送中间结果和分段结果，最终记录锁定并签名
+	Exam bool
+
+	// OnComplete 最终结果发送给客户端之前调用，用于保存评测记录
+	OnComplete func(s *Session, result *SOEResult)
+
+	// OnResponse 每条响应发送给客户端之后调用，用于实时监控
+	OnResponse func(s *Session, response AssessmentResponse)
+
+	// Upstream 启动识别器的超时和重试配置，为 nil 时不限时、不重试
+	Upstream *upstream.Options
+
+	engines  []*Engine
+	language string
+	segments []*SegmentResult
+
+	mu          sync.Mutex
+	writeMu     sync.Mutex
+	listeners   []*StreamListener
+	recognizers []Recognizer
+	current     int
+	result      *SOEResult
+
+	// audioMu 保证重放音频时不会与客户端的新音频交错，持有期间不访问上游
+	audioMu  sync.Mutex
+	chunks   [][]byte   // 已接收的原始音频，用于切换引擎后重放
+	starts   []int      // 每段开始时的 chunks 下标
+	engine   int        // 当前使用的引擎
+	switches int        // 切换引擎的次数
+	pending  int        // 正在切换引擎的段数
+	switched *sync.Cond // 段切换引擎结束时通知
+	finished bool
+
+	format  audio.Format
+	vad     *audio.VAD
+	samples int64
+	pcm     []int16
+}
+
+// NewSession engines 为按顺序使用的引擎，第一个为主引擎
+func NewSession(conn *websocket.Conn, req *AssessmentRequest, engines []*Engine) *Session {
+	language, _ := EngineLanguage(req.ServerEngineType)
+	mode, _ := LookupMode(req.EvalMode, language)
+
+	s := &Session{
+		ID:        store.NewID(),
+		Conn:      conn,
+		Request:   req,
+		ErrorChan: make(chan error, 1),
+		Complete:  make(chan struct{}),
+		engines:   engines,
+		language:  language,
+		format:    req.AudioFormat(),
+		starts:    []int{0},
+	}
+	s.switched = sync.NewCond(&s.mu)
+	s.vad = audio.NewVAD(s.format.SampleRate)
+
+	for i, text := range SplitText(mode, req.RefText) {
+		s.segments = append(s.segments, &SegmentResult{Index: i, RefText: text})
+	}
+	if s.Segmented() {
+		log.Printf("参考文本过长，拆分为 %d 段评测", len(s.segments))
+	}
+
+	return s
+}
+
+// Segmented 是否为分段评测
+func (s *Session) Segmented() bool {
+	return len(s.segments) > 1
+}
+
+// Start 启动第一段的识别器
+func (s *Session) Start() error {
+	return s.openSegment(0)
+}
+
+// Write 将音频发送到当前段的识别器，检测到句间停顿且当前段已读完时切换到下一段
+func (s *Session) Write(data []byte) error {
+	s.audioMu.Lock()
+	s.mu.Lock()
+	s.chunks = append(s.chunks, data)
+	current := s.current
+	recognizer := s.recognizers[current]
+	listener := s.listeners[current]
+	last := current == len(s.segments)-1
+	s.mu.Unlock()
+
+	// 正在切换引擎时只缓存音频；识别器已失败时切换引擎，重放的音频包含本次数据
+	if recognizer != nil {
+		if err := recognizer.Write(data); err != nil && !s.fallback(current, listener, err) {
+			s.audioMu.Unlock()
+			return err
+		}
+	}
+	s.audioMu.Unlock()
+
+	samples, format, err := audio.Decode(data, s.format)
+	if err != nil {
+		log.Printf("音频解析失败，跳过分段检测: %v", err)
+		return nil
+	}
+	s.mu.Lock()
+	s.format = format
+	s.samples += int64(len(samples))
+	s.pcm = append(s.pcm, samples...)
+	s.mu.Unlock()
+
+	if last {
+		return nil
+	}
+
+	if s.vad.Write(samples) && s.segmentDone() {
+		return s.advance()
+	}
+
+	return nil
+}
+
+// Finish 客户端音频结束：停止当前识别器，等待所有段完成后发送汇总结果
+// 等待期间切换了引擎时继续等待新的识别器。
+func (s *Session) Finish() {
+	// 当前段正在切换引擎时，由重放结束后停止新的识别器
+	s.audioMu.Lock()
+	s.mu.Lock()
+	s.finished = true
+	recognizer := s.recognizers[s.current]
+	s.mu.Unlock()
+	s.audioMu.Unlock()
+
+	if recognizer != nil {
+		recognizer.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(resultTimeout):
+		err := errno.ErrUpstreamTimeout.WithRawErr(fmt.Errorf("no result %v after the audio ended", resultTimeout))
+		log.Printf("等待评测结果超时: %v", err)
+		s.abandon(err)
+		return
+	}
+
+	if s.Segmented() {
+		result := Aggregate(s.language, s.segments)
+		result.Engine = s.Engine()
+		finalize(result, s.Scorer, s.Feedback)
+		s.complete(result)
+		log.Printf("分段评测汇总: 整体得分=%.2f, 准确率=%.2f, 流畅度=%.2f, 完整度=%.2f",
+			result.OverallScore, result.PronAccuracy, result.PronFluency, result.PronCompletion)
+		response := AssessmentResponse{
+			Status:    "complete",
+			SessionID: s.ID,
+			Result:    result,
+		}
+		writeResponse(s.Conn, &s.writeMu, response)
+		s.publish(response)
+	}
+
+	close(s.Complete)
+}
+
+// wait 等待所有段的监听器完成，期间切换了引擎时继续等待新的识别器
+func (s *Session) wait() {
+	for {
+		s.mu.Lock()
+		for s.pending > 0 {
+			s.switched.Wait()
+		}
+		listeners := append([]*StreamListener(nil), s.listeners...)
+		switches := s.switches
+		s.mu.Unlock()
+
+		for _, l := range listeners {
+			<-l.Complete
+		}
+
+		s.mu.Lock()
+		switched := s.switches != switches
+		s.mu.Unlock()
+		if !switched {
+			return
+		}
+	}
+}
+
+// abandon 放弃尚未完成的监听器，由第一个未完成的监听器向客户端上报错误
+func (s *Session) abandon(err error) {
+	s.mu.Lock()
+	listeners := append([]*StreamListener(nil), s.listeners...)
+	s.mu.Unlock()
+
+	reported := false
+	for _, l := range listeners {
+		select {
+		case <-l.Complete:
+			continue
+		default:
+		}
+		if !reported {
+			l.fail(err)
+			reported = true
+		}
+		l.detach()
+		l.completeOnce.Do(func() { close(l.Complete) })
+	}
+
+	// 监听器都已完成时为切换引擎未结束
+	if !reported {
+		response := NewErrorResponse(err)
+		response.SessionID = s.ID
+		writeResponse(s.Conn, &s.writeMu, response)
+		s.publish(response)
+		s.Fail(err)
+	}
+}
+
+// Result 返回会话的最终结果，尚未完成时返回 nil
+func (s *Session) Result() *SOEResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.result
+}
+
+// Samples 返回已接收的全部音频（单声道）及其格式
+func (s *Session) Samples() ([]int16, audio.Format) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pcm, s.format
+}
+
+// Fail 上报会话错误，已有未处理的错误时忽略
+func (s *Session) Fail(err error) {
+	select {
+	case s.ErrorChan <- err:
+	default:
+	}
+}
+
+// Close 停止所有识别器
+func (s *Session) Close() {
+	s.mu.Lock()
+	recognizers := append([]Recognizer(nil), s.recognizers...)
+	s.mu.Unlock()
+
+	for _, r := range recognizers {
+		if r != nil {
+			r.Stop()
+		}
+	}
+}
+
+// openSegment 启动新的段并设为当前段
+func (s *Session) openSegment(i int) error {
+	listener, recognizer, err := s.startSegment(i, false)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.listeners = append(s.listeners, listener)
+	s.recognizers = append(s.recognizers, recognizer)
+	s.current = i
+	s.mu.Unlock()
+
+	s.vad.Reset()
+	return nil
+}
+
+// startSegment 启动段的识别器，启动失败时依次切换到后续引擎
+// restart 为切换引擎后重新启动，监听器不发送开始消息。
+func (s *Session) startSegment(i int, restart bool) (*StreamListener, Recognizer, error) {
+	for {
+		s.mu.Lock()
+		engine := s.engines[s.engine]
+		s.mu.Unlock()
+
+		listener, recognizer, err := s.startEngine(i, engine, restart)
+		if err == nil {
+			return listener, recognizer, nil
+		}
+		if !canFallback(err) {
+			return nil, nil, err
+		}
+
+		s.mu.Lock()
+		changed, ok := s.nextEngine(engine.Name)
+		s.mu.Unlock()
+		if !ok {
+			return nil, nil, err
+		}
+		if changed {
+			s.notifyEngine(engine.Name, err)
+		}
+	}
+}
+
+// startEngine 使用引擎启动段的识别器
+// 每次尝试使用新的识别器和监听器，超时放弃的识别器不会影响后续尝试。
+func (s *Session) startEngine(i int, engine *Engine, replay bool) (*StreamListener, Recognizer, error) {
+	var (
+		seg        = s.segments[i]
+		listener   *StreamListener
+		recognizer Recognizer
+	)
+	start := func() (err error) {
+		listener = s.newListener(seg, engine)
+		listener.replay = replay
+		req := s.segmentRequest(seg, engine, listener)
+		if recognizer, err = engine.NewRecognizer(&req, listener); err != nil {
+			return err
+		}
+		return s.startRecognizer(recognizer, listener)
+	}
+
+	var err error
+	if s.Upstream != nil && engine.Breaker != nil {
+		err = upstream.Do(engine.Breaker, s.Upstream, start)
+	} else {
+		err = start()
+	}
+	return listener, recognizer, err
+}
+
+// newListener 创建段的监听器
+func (s *Session) newListener(seg *SegmentResult, engine *Engine) *StreamListener {
+	listener := NewStreamListener(s.Conn)
+	listener.Engine = engine.Name
+	listener.index = seg.Index
+	listener.onFail = s.onFail
+	listener.ErrorChan = s.ErrorChan
+	listener.writeMu = &s.writeMu
+	listener.Scorer = s.Scorer
+	listener.Feedback = s.Feedback
+	listener.SessionID = s.ID
+	listener.onResponse = s.publish
+	listener.hideIntermediate = s.Exam
+
+	if s.Segmented() {
+		listener.Segment = seg
+	} else {
+		listener.onFinal = s.complete
+	}
+	return listener
+}
+
+// segmentRequest 段的评测参数，参考文本规范化后由监听器映射回原始单词
+func (s *Session) segmentRequest(seg *SegmentResult, engine *Engine, listener *StreamListener) AssessmentRequest {
+	req := *s.Request
+	if len(engine.ServerEngineType) > 0 {
+		req.ServerEngineType = engine.ServerEngineType
+	}
+	if s.Segmented() {
+		req.RefText = seg.RefText
+		req.EvalMode = SentenceMode(s.language).EvalMode()
+	}
+
+	listener.Text = Normalize(req.RefText, s.language)
+	if listener.Text.Changed() {
+		log.Printf("参考文本已规范化: %s => %s", req.RefText, listener.Text.Text)
+	}
+	req.RefText = listener.Text.Text
+
+	return req
+}
+
+// startRecognizer 在连接超时时间内启动识别器
+// 超时后监听器不再向客户端发送响应，识别器稍后启动成功时在后台停止。
+func (s *Session) startRecognizer(recognizer Recognizer, listener *StreamListener) error {
+	if s.Upstream == nil {
+		return recognizer.Start()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- recognizer.Start()
+	}()
+
+	timeout := s.Upstream.Timeout()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		listener.detach()
+		go func() {
+			if err := <-done; err == nil {
+				recognizer.Stop()
+			}
+		}()
+		return errno.ErrUpstreamTimeout.WithRawErr(fmt.Errorf("recognizer start timed out after %v", timeout))
+	}
+}
+
+// publish 调用 OnResponse
+func (s *Session) publish(response AssessmentResponse) {
+	if s.OnResponse != nil {
+		s.OnResponse(s, response)
+	}
+}
+
+// complete 记录最终结果并调用 OnComplete
+func (s *Session) complete(result *SOEResult) {
+	s.mu.Lock()
+	s.result = result
+	s.mu.Unlock()
+
+	if s.OnComplete != nil {
+		s.OnComplete(s, result)
+	}
+}
+
+// segmentDone 当前段是否已读完：中间结果已覆盖最后一个单词，或语音时长已达到估算值
+func (s *Session) segmentDone() bool {
+	s.mu.Lock()
+	seg := s.segments[s.current]
+	listener := s.listeners[s.current]
+	s.mu.Unlock()
+
+	if coversLastWord(listener.Result(), seg.RefText, s.language) {
+		return true
+	}
+
+	perWord := enSecondsPerWord
+	if s.language == "zh" {
+		perWord = zhSecondsPerChar
+	}
+	return s.vad.SpeechDuration() >= float64(CountWords(seg.RefText, s.language))*perWord
+}
+
+// advance 结束当前段并开始下一段，上一段的结果由监听器异步回调
+// 当前段正在切换引擎时，由重放结束后停止新的识别器。
+func (s *Session) advance() error {
+	s.audioMu.Lock()
+	s.mu.Lock()
+	prev := s.recognizers[s.current]
+	next := s.current + 1
+	if s.format.SampleRate > 0 {
+		s.segments[next].Offset = s.samples * 1000 / int64(s.format.SampleRate)
+	}
+	s.starts = append(s.starts, len(s.chunks))
+	s.mu.Unlock()
+	s.audioMu.Unlock()
+
+	log.Printf("检测到句间停顿，切换到第 %d/%d 段", next+1, len(s.segments))
+	if prev != nil {
+		go prev.Stop()
+	}
+
+	return s.openSegment(next)
+}
@@ -1,171 +1,393 @@
-package speech
-
-import (
-	"strings"
-	"unicode"
-)
-
-// 评测模式类型
-type ModeType int64
-
-const (
-	// 英文模式
-	EngWord         ModeType = 0
-	EngSentence     ModeType = 1
-	EngParagraph    ModeType = 2
-	EngFreeTalk     ModeType = 3
-	EngWordCorrect  ModeType = 4
-	EngScenario     ModeType = 5
-	EngMultiBranch  ModeType = 6
-	EngRealTimeWord ModeType = 7
-
-	// 中文模式
-	ChnWord         ModeType = 11
-	ChnSentence     ModeType = 22
-	ChnParagraph    ModeType = 33
-	ChnFreeTalk     ModeType = 44
-	ChnScenario     ModeType = 55
-	ChnMultiBranch  ModeType = 66
-	ChnRealTimeWord ModeType = 77
-	ChnPinyin       ModeType = 88
-)
-
-// 评测模式配置（根据文档规则定义）
-var modeRules = map[ModeType]struct {
-	language    string // 语言类型（en/zh）
-	minWords    int    // 最小单词/字符数（0表示不限）
-	maxWords    int    // 最大单词/字符数（0表示不限）
-	hasSpace    bool   // 是否包含空格（英文适用）
-	hasBranch   bool   // 是否包含分支（如A./B.）
-	hasPhonetic bool   // 是否包含音标（/.../）
-	isPinyin    bool   // 是否为拼音
-	isRealTime  bool   // 是否为实时多单词模式
-}{
-	// 英文模式
-	EngWord: {
-		language:    "en",
-		minWords:    1,
-		maxWords:    1,
-		hasSpace:    false,
-		hasPhonetic: true, // 支持音标
-	},
-	EngSentence: {
-		language: "en",
-		minWords: 2,
-		maxWords: 30,
-		hasSpace: true,
-	},
-	EngParagraph: {
-		language: "en",
-		minWords: 31,
-		maxWords: 120,
-		hasSpace: true,
-	},
-	EngFreeTalk: {
-		language: "en",
-		maxWords: 0, // 不限字数
-	},
-}
-
-// DetectEvalMode 检测评测模式
-func DetectEvalMode(input string) int64 {
-	// 1. 判断语言类型
-	language := detectLanguage(input)
-
-	// 2. 预处理文本（去空格、标点，提取分支）
-	cleanInput := strings.TrimSpace(input)
-	hasBranch := strings.ContainsAny(cleanInput, "/\\|") // 分支分隔符
-	// hasPhonetic := regexp.MustCompile(`/.*?/`).MatchString(cleanInput) // 检测音标格式
-	// isPinyin := isPurePinyin(cleanInput)                               // 纯拼音检测
-
-	// 3. 统计字数/单词数
-	wordCount := countWords(cleanInput, language)
-
-	// 4. 按语言过滤模式
-	var possibleModes []ModeType
-	for mode, rule := range modeRules {
-		if rule.language != language {
-			continue
-		}
-		possibleModes = append(possibleModes, mode)
-	}
-
-	// 5. 匹配具体模式
-	for _, mode := range possibleModes {
-		rule := modeRules[mode]
-
-		// 检查字数限制
-		if !checkWordCount(wordCount, rule.minWords, rule.maxWords) {
-			continue
-		}
-
-		// 检查空格
-		if rule.hasSpace && !strings.Contains(cleanInput, " ") {
-			continue
-		}
-		if rule.hasSpace && rule.isRealTime { // 实时模式无空格
-			continue
-		}
-
-		// 检查分支
-		if rule.hasBranch && !hasBranch {
-			continue
-		}
-
-		// 实时模式特殊处理（多个单词但无空格，按分支数判断）
-		if rule.isRealTime {
-			branchCount := len(strings.Split(cleanInput, "/")) // 假设分支用/分隔
-			if branchCount >= 2 && wordCount == branchCount {  // 每个分支1个单词/汉字
-				return int64(mode)
-			}
-			continue
-		}
-
-		// 自由说模式：字数不限且无分支/音标
-		if mode == EngFreeTalk || mode == ChnFreeTalk {
-			if !hasBranch {
-				return int64(mode)
-			}
-			continue
-		}
-
-		// 其他模式默认匹配
-		return int64(mode)
-	}
-
-	return 0 // 未匹配到模式
-}
-
-// 检测语言类型（英文/中文）
-func detectLanguage(input string) string {
-	for _, r := range input {
-		if unicode.Is(unicode.Han, r) { // 检测汉字
-			return "zh"
-		}
-		if unicode.IsLetter(r) && unicode.IsLower(r) { // 检测英文字母
-			return "en"
-		}
-	}
-	return "" // 无法识别的语言
-}
-
-// 统计单词数（英文按空格分割，中文按字符数）
-func countWords(input, language string) int {
-	if language == "en" {
-		return len(strings.Fields(input)) // 按空格分割单词
-	} else if language == "zh" {
-		return len([]rune(input)) // 中文按字符数
-	}
-	return 0
-}
-
-// 检查字数是否符合范围（maxWords=0表示不限）
-func checkWordCount(count, min, max int) bool {
-	if min > 0 && count < min {
-		return false
-	}
-	if max > 0 && count > max {
-		return false
-	}
-	return true
-}
+package speech
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 评测模式类型
+type ModeType int64
+
+const (
+	// 英文模式
+	EngWord         ModeType = 0
+	EngSentence     ModeType = 1
+	EngParagraph    ModeType = 2
+	EngFreeTalk     ModeType = 3
+	EngWordCorrect  ModeType = 4
+	EngScenario     ModeType = 5
+	EngMultiBranch  ModeType = 6
+	EngRealTimeWord ModeType = 7
+
+	// 中文模式
+	ChnWord         ModeType = 11
+	ChnSentence     ModeType = 22
+	ChnParagraph    ModeType = 33
+	ChnFreeTalk     ModeType = 44
+	ChnScenario     ModeType = 55
+	ChnMultiBranch  ModeType = 66
+	ChnRealTimeWord ModeType = 77
+	ChnPinyin       ModeType = 88
+)
+
+// EvalModeAuto 客户端未指定 eval_mode 时的取值，由服务端根据参考文本自动检测
+const EvalModeAuto int64 = -1
+
+// modeRule 评测模式规则
+type modeRule struct {
+	language    string // 语言类型（en/zh）
+	evalMode    int64  // 对应上游接口的 eval_mode
+	minWords    int    // 最小单词/字符数（0表示不限）
+	maxWords    int    // 最大单词/字符数（0表示不限）
+	hasSpace    bool   // 是否包含空格（英文适用）
+	hasBranch   bool   // 是否包含分支（如 A|B 或 A/B）
+	hasPhonetic bool   // 是否包含音标（/.../）
+	isPinyin    bool   // 是否为拼音
+	isRealTime  bool   // 是否为实时多单词模式
+	manual      bool   // 仅允许客户端显式指定，不参与自动检测
+	splittable  bool   // 超出字数限制时拆分为多句依次评测
+}
+
+// 评测模式配置（根据文档规则定义）
+var modeRules = map[ModeType]modeRule{
+	// 英文模式
+	EngWord: {
+		language:    "en",
+		evalMode:    0,
+		minWords:    1,
+		maxWords:    1,
+		hasSpace:    false,
+		hasPhonetic: true, // 支持音标
+	},
+	EngSentence: {
+		language: "en",
+		evalMode: 1,
+		minWords: 2,
+		maxWords: 30,
+		hasSpace: true,
+	},
+	EngParagraph: {
+		language:   "en",
+		evalMode:   2,
+		minWords:   31,
+		maxWords:   120,
+		splittable: true,
+		hasSpace:   true,
+	},
+	EngFreeTalk: {
+		language: "en",
+		evalMode: 3,
+		maxWords: 0, // 不限字数
+	},
+	EngWordCorrect: {
+		language: "en",
+		evalMode: 4,
+		minWords: 1,
+		maxWords: 1,
+		manual:   true, // 与单词模式文本相同，需客户端指定
+	},
+	EngScenario: {
+		language: "en",
+		evalMode: 5,
+		maxWords: 120,
+		manual:   true, // 情景评测依赖题目上下文，需客户端指定
+	},
+	EngMultiBranch: {
+		language:  "en",
+		evalMode:  6,
+		hasBranch: true,
+	},
+	EngRealTimeWord: {
+		language:   "en",
+		evalMode:   7,
+		hasBranch:  true,
+		isRealTime: true,
+	},
+
+	// 中文模式
+	ChnWord: {
+		language: "zh",
+		evalMode: 0,
+		minWords: 1,
+		maxWords: 4,
+	},
+	ChnSentence: {
+		language: "zh",
+		evalMode: 1,
+		minWords: 5,
+		maxWords: 30,
+	},
+	ChnParagraph: {
+		language:   "zh",
+		evalMode:   2,
+		minWords:   31,
+		maxWords:   120,
+		splittable: true,
+	},
+	ChnFreeTalk: {
+		language: "zh",
+		evalMode: 3,
+		maxWords: 0, // 不限字数
+	},
+	ChnScenario: {
+		language: "zh",
+		evalMode: 5,
+		maxWords: 120,
+		manual:   true,
+	},
+	ChnMultiBranch: {
+		language:  "zh",
+		evalMode:  6,
+		hasBranch: true,
+	},
+	ChnRealTimeWord: {
+		language:   "zh",
+		evalMode:   7,
+		hasBranch:  true,
+		isRealTime: true,
+	},
+	ChnPinyin: {
+		language: "zh",
+		evalMode: 8,
+		isPinyin: true,
+	},
+}
+
+// 自动检测时的匹配顺序，分支类模式优先于按字数区分的模式
+var modeOrder = []ModeType{
+	ChnPinyin,
+	EngRealTimeWord, EngMultiBranch, EngWord, EngSentence, EngParagraph, EngFreeTalk,
+	ChnRealTimeWord, ChnMultiBranch, ChnWord, ChnSentence, ChnParagraph, ChnFreeTalk,
+	EngWordCorrect, EngScenario, ChnScenario,
+}
+
+var (
+	// 音标格式：以空白或行首开始的 /.../
+	phoneticPattern = regexp.MustCompile(`(^|\s)/[^/\s][^/]*/`)
+
+	// 拼音音节（声调已转换为数字或省略）
+	pinyinSyllable = regexp.MustCompile(`^(zh|ch|sh|[bpmfdtnlgkhjqxrzcsyw])?[aeiouv]+(ng|n|r)?[1-5]?$`)
+
+	// 带声调的元音
+	toneMarks = strings.NewReplacer(
+		"ā", "a1", "á", "a2", "ǎ", "a3", "à", "a4",
+		"ō", "o1", "ó", "o2", "ǒ", "o3", "ò", "o4",
+		"ē", "e1", "é", "e2", "ě", "e3", "è", "e4",
+		"ī", "i1", "í", "i2", "ǐ", "i3", "ì", "i4",
+		"ū", "u1", "ú", "u2", "ǔ", "u3", "ù", "u4",
+		"ǖ", "v1", "ǘ", "v2", "ǚ", "v3", "ǜ", "v4", "ü", "v",
+	)
+)
+
+// Language 评测模式对应的语言（en/zh）
+func (m ModeType) Language() string {
+	return modeRules[m].language
+}
+
+// EvalMode 评测模式对应的上游 eval_mode 取值
+func (m ModeType) EvalMode() int64 {
+	return modeRules[m].evalMode
+}
+
+// EngineType 评测模式对应的默认引擎类型
+func (m ModeType) EngineType() string {
+	if m.Language() == "zh" {
+		return "16k_zh"
+	}
+	return "16k_en"
+}
+
+// DetectEvalMode 检测评测模式，返回上游 eval_mode 取值
+func DetectEvalMode(input string) int64 {
+	return DetectMode(input).EvalMode()
+}
+
+// DetectMode 检测评测模式
+func DetectMode(input string) ModeType {
+	// 1. 预处理文本（去空格，提取音标、分支）
+	cleanInput := strings.TrimSpace(input)
+	if isPurePinyin(cleanInput) {
+		return ChnPinyin
+	}
+
+	hasPhonetic := phoneticPattern.MatchString(cleanInput)
+	plainInput := strings.TrimSpace(phoneticPattern.ReplaceAllString(cleanInput, " "))
+	branches := splitBranches(plainInput)
+
+	// 2. 判断语言类型
+	language := detectLanguage(plainInput)
+
+	// 3. 统计字数/单词数
+	wordCount := countWords(plainInput, language)
+
+	// 4. 按顺序匹配具体模式
+	for _, mode := range modeOrder {
+		rule := modeRules[mode]
+		if rule.language != language || rule.manual || rule.isPinyin {
+			continue
+		}
+
+		// 检查分支
+		if rule.hasBranch != (len(branches) > 1) {
+			continue
+		}
+
+		// 检查音标
+		if hasPhonetic && !rule.hasPhonetic {
+			continue
+		}
+
+		// 实时模式：每个分支1个单词/汉字
+		if rule.isRealTime {
+			if allSingleWord(branches, language) {
+				return mode
+			}
+			continue
+		}
+
+		// 多分支模式
+		if rule.hasBranch {
+			return mode
+		}
+
+		// 检查字数限制（可拆分的模式不限最大字数）
+		maxWords := rule.maxWords
+		if rule.splittable {
+			maxWords = 0
+		}
+		if !checkWordCount(wordCount, rule.minWords, maxWords) {
+			continue
+		}
+
+		// 检查空格
+		if rule.hasSpace && !strings.Contains(plainInput, " ") {
+			continue
+		}
+
+		return mode
+	}
+
+	return EngWord // 未匹配到模式
+}
+
+// 检测语言类型（英文/中文）
+// 混合文本按汉字数与英文单词数的多数决定，数量相同时视为中文。
+func detectLanguage(input string) string {
+	var han, latin int
+	inWord := false
+	for _, r := range input {
+		switch {
+		case unicode.Is(unicode.Han, r): // 检测汉字
+			han++
+			inWord = false
+		case unicode.Is(unicode.Latin, r): // 检测英文字母（不区分大小写）
+			if !inWord {
+				latin++
+			}
+			inWord = true
+		case r == '\'' || r == '-':
+			// 单词内的撇号和连字符
+		default:
+			inWord = false
+		}
+	}
+
+	switch {
+	case han == 0 && latin == 0:
+		return "" // 无法识别的语言
+	case han >= latin:
+		return "zh"
+	default:
+		return "en"
+	}
+}
+
+// 统计单词数（英文按空格分割，中文按汉字数）
+func countWords(input, language string) int {
+	if language == "en" {
+		var count int
+		for _, field := range strings.Fields(input) {
+			if strings.IndexFunc(field, isWordRune) >= 0 { // 忽略纯标点
+				count++
+			}
+		}
+		return count
+	} else if language == "zh" {
+		var count int
+		for _, r := range input {
+			if unicode.Is(unicode.Han, r) {
+				count++
+			}
+		}
+		return count
+	}
+	return 0
+}
+
+// 检查字数是否符合范围（maxWords=0表示不限）
+func checkWordCount(count, min, max int) bool {
+	if min > 0 && count < min {
+		return false
+	}
+	if max > 0 && count > max {
+		return false
+	}
+	return true
+}
+
+// splitBranches 按分支分隔符拆分文本，忽略空分支
+// | 总是作为分隔符；/ 只在不含空白的文本中作为分隔符（如 apple/banana），避免误判 and/or 这类写法。
+func splitBranches(input string) []string {
+	slashSep := strings.IndexFunc(input, unicode.IsSpace) < 0
+
+	var branches []string
+	for _, b := range strings.FieldsFunc(input, func(r rune) bool {
+		return r == '|' || (slashSep && r == '/')
+	}) {
+		if b = strings.TrimSpace(b); len(b) > 0 {
+			branches = append(branches, b)
+		}
+	}
+	return branches
+}
+
+// allSingleWord 每个分支是否只包含1个单词/汉字
+func allSingleWord(branches []string, language string) bool {
+	for _, b := range branches {
+		if countWords(b, language) != 1 {
+			return false
+		}
+	}
+	return len(branches) > 1
+}
+
+// isPurePinyin 纯拼音检测，每个音节都必须带声调（数字或声调符号）
+func isPurePinyin(input string) bool {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 0 {
+		return false
+	}
+
+	for _, field := range fields {
+		syllable := toneMarks.Replace(strings.Trim(field, ",.?!;:，。？！；："))
+		// 声调符号转换后数字位于元音之后，统一移到音节末尾
+		var tone string
+		syllable = strings.Map(func(r rune) rune {
+			if r >= '1' && r <= '5' {
+				tone = string(r)
+				return -1
+			}
+			return r
+		}, syllable)
+		if len(tone) == 0 || !pinyinSyllable.MatchString(syllable+tone) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,67 @@
+package speech
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectMode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ModeType
+	}{
+		{"hello", EngWord},
+		{"  hello  ", EngWord},
+		{"hello /həˈləʊ/", EngWord},
+		{"Hello world", EngSentence},
+		{"I have 3 apples.", EngSentence},
+		{"you and/or me", EngSentence},
+		{"ni hao", EngSentence},
+		{strings.Repeat("word ", 30), EngSentence},
+		{strings.Repeat("word ", 31), EngParagraph},
+		{strings.Repeat("word ", 200), EngParagraph},
+		{"apple|banana", EngRealTimeWord},
+		{"apple/banana", EngRealTimeWord},
+		{"I like apples|I like pears", EngMultiBranch},
+		{"你好", ChnWord},
+		{"我喜欢 apple", ChnWord},
+		{"今天天气很好", ChnSentence},
+		{strings.Repeat("好", 30), ChnSentence},
+		{strings.Repeat("好", 31), ChnParagraph},
+		{"我|你", ChnRealTimeWord},
+		{"苹果|香蕉", ChnMultiBranch},
+		{"ni3 hao3", ChnPinyin},
+		{"nǐ hǎo", ChnPinyin},
+		{"", EngWord},
+	}
+
+	for _, tt := range tests {
+		if got := DetectMode(tt.input); got != tt.want {
+			t.Errorf("DetectMode(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestModeType(t *testing.T) {
+	tests := []struct {
+		mode       ModeType
+		evalMode   int64
+		engineType string
+	}{
+		{EngWord, 0, "16k_en"},
+		{EngParagraph, 2, "16k_en"},
+		{EngRealTimeWord, 7, "16k_en"},
+		{ChnWord, 0, "16k_zh"},
+		{ChnMultiBranch, 6, "16k_zh"},
+		{ChnPinyin, 8, "16k_zh"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.EvalMode(); got != tt.evalMode {
+			t.Errorf("ModeType(%d).EvalMode() = %d, want %d", tt.mode, got, tt.evalMode)
+		}
+		if got := tt.mode.EngineType(); got != tt.engineType {
+			t.Errorf("ModeType(%d).EngineType() = %q, want %q", tt.mode, got, tt.engineType)
+		}
+	}
+}
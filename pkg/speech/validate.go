@@ -0,0 +1,131 @@
+package speech
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"lingolift/errno"
+)
+
+const (
+	// 评分系数取值范围（上游接口要求 [1.0, 4.0]）
+	MinScoreCoeff = 1.0
+	MaxScoreCoeff = 4.0
+
+	// 文本模式取值范围：0 普通文本，1 音素结构文本，2 音素注册模式
+	MinTextMode = 0
+	MaxTextMode = 2
+)
+
+// engineLanguages 支持的引擎类型及其语言
+var engineLanguages = map[string]string{
+	"16k_en": "en",
+	"16k_zh": "zh",
+}
+
+// EngineLanguage 返回引擎类型对应的语言，不支持的引擎返回 false
+func EngineLanguage(engineType string) (string, bool) {
+	language, ok := engineLanguages[engineType]
+	return language, ok
+}
+
+// LookupMode 根据上游 eval_mode 和语言查找评测模式
+func LookupMode(evalMode int64, language string) (ModeType, bool) {
+	for _, mode := range modeOrder {
+		rule := modeRules[mode]
+		if rule.language == language && rule.evalMode == evalMode {
+			return mode, true
+		}
+	}
+	return 0, false
+}
+
+// CountWords 统计参考文本的单词/汉字数，忽略音标
+func CountWords(text, language string) int {
+	return countWords(phoneticPattern.ReplaceAllString(text, " "), language)
+}
+
+// CheckWordLimit 检查参考文本是否超出评测模式的字数限制
+// 可拆分的模式（段落）不检查总字数，由 SplitText 拆分后逐段评测。
+func CheckWordLimit(mode ModeType, text string) error {
+	rule := modeRules[mode]
+	if rule.splittable {
+		return nil
+	}
+	count := CountWords(text, rule.language)
+	if !checkWordCount(count, 0, rule.maxWords) {
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf(
+			"ref_text has %d words, eval_mode %d allows at most %d.", count, rule.evalMode, rule.maxWords))
+	}
+	return nil
+}
+
+// fillDefault 按 `default` 标签为零值字段设置默认值
+func fillDefault(v interface{}) {
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		tag, ok := rt.Field(i).Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+
+		field := rv.Field(i)
+		if !field.CanSet() || !field.IsZero() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(tag)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n, err := strconv.ParseInt(tag, 10, 64); err == nil {
+				field.SetInt(n)
+			}
+		case reflect.Float32, reflect.Float64:
+			if f, err := strconv.ParseFloat(tag, 64); err == nil {
+				field.SetFloat(f)
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(tag); err == nil {
+				field.SetBool(b)
+			}
+		}
+	}
+}
+
+// checkRequired 检查 `validate:"required"` 标签的字段是否为空
+func checkRequired(v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		if rt.Field(i).Tag.Get("validate") != "required" {
+			continue
+		}
+
+		field := rv.Field(i)
+		if field.Kind() == reflect.String && len(strings.TrimSpace(field.String())) > 0 {
+			continue
+		}
+		if field.Kind() != reflect.String && !field.IsZero() {
+			continue
+		}
+
+		return errno.ErrInvalidParameterValue.WithFmt(fmt.Sprintf("%s is required.", jsonName(rt.Field(i))))
+	}
+
+	return nil
+}
+
+// jsonName 返回字段的 json 名称
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if len(name) == 0 {
+		return f.Name
+	}
+	return name
+}
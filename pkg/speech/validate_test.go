@@ -0,0 +1,98 @@
+package speech
+
+import (
+	"strings"
+	"testing"
+
+	"lingolift/errno"
+)
+
+func TestFillDefault(t *testing.T) {
+	req := AssessmentRequest{RefText: "hello", ServerEngineType: "16k_zh", EvalMode: 1}
+	req.FillDefault()
+
+	if req.ServerEngineType != "16k_zh" {
+		t.Errorf("ServerEngineType = %q, want the client value 16k_zh", req.ServerEngineType)
+	}
+	if req.EvalMode != 1 {
+		t.Errorf("EvalMode = %d, want the client value 1", req.EvalMode)
+	}
+	if req.SampleRate != 16000 || req.BitRate != 16 {
+		t.Errorf("SampleRate, BitRate = %d, %d, want 16000, 16", req.SampleRate, req.BitRate)
+	}
+
+	req = AssessmentRequest{}
+	req.FillDefault()
+	if req.ServerEngineType != "16k_en" {
+		t.Errorf("ServerEngineType = %q, want 16k_en", req.ServerEngineType)
+	}
+}
+
+func TestValidator(t *testing.T) {
+	valid := func() AssessmentRequest {
+		return AssessmentRequest{
+			RefText:          "How are you today",
+			ServerEngineType: "16k_en",
+			EvalMode:         1,
+			ScoreCoeff:       1.0,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *AssessmentRequest)
+		field  string // 错误信息中的字段名，为空表示校验通过
+	}{
+		{"valid", func(req *AssessmentRequest) {}, ""},
+		{"missing ref_text", func(req *AssessmentRequest) { req.RefText = "" }, "ref_text"},
+		{"blank ref_text", func(req *AssessmentRequest) { req.RefText = "   " }, "ref_text"},
+		{"unsupported engine", func(req *AssessmentRequest) { req.ServerEngineType = "8k_en" }, "server_engine_type"},
+		{"unsupported eval_mode", func(req *AssessmentRequest) { req.EvalMode = 9 }, "eval_mode"},
+		{"pinyin mode needs zh engine", func(req *AssessmentRequest) { req.EvalMode = 8 }, "eval_mode"},
+		{"negative text_mode", func(req *AssessmentRequest) { req.TextMode = -1 }, "text_mode"},
+		{"text_mode too large", func(req *AssessmentRequest) { req.TextMode = 3 }, "text_mode"},
+		{"score_coeff too small", func(req *AssessmentRequest) { req.ScoreCoeff = 0.5 }, "score_coeff"},
+		{"score_coeff too large", func(req *AssessmentRequest) { req.ScoreCoeff = 4.5 }, "score_coeff"},
+		{"score_coeff upper bound", func(req *AssessmentRequest) { req.ScoreCoeff = 4.0 }, ""},
+		{"word mode with two words", func(req *AssessmentRequest) { req.EvalMode = 0; req.RefText = "hello world" }, "ref_text"},
+		{"sentence too long", func(req *AssessmentRequest) { req.RefText = strings.Repeat("word ", 31) }, "ref_text"},
+		{"paragraph is split instead of rejected", func(req *AssessmentRequest) {
+			req.EvalMode = 2
+			req.RefText = strings.Repeat("word ", 200)
+		}, ""},
+		{"phonetic hints are not counted", func(req *AssessmentRequest) {
+			req.EvalMode = 0
+			req.RefText = "tomato /təˈmɑːtəʊ/"
+		}, ""},
+		{"zh word mode", func(req *AssessmentRequest) {
+			req.ServerEngineType = "16k_zh"
+			req.EvalMode = 0
+			req.RefText = "你好世界啊"
+		}, "ref_text"},
+	}
+
+	for _, tt := range tests {
+		req := valid()
+		tt.modify(&req)
+		err := req.Validator()
+
+		if len(tt.field) == 0 {
+			if err != nil {
+				t.Errorf("%s: Validator() = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+
+		e, ok := err.(errno.Err)
+		if !ok {
+			t.Errorf("%s: Validator() = %v, want errno.Err", tt.name, err)
+			continue
+		}
+		if e.Code != errno.ErrInvalidParameterValue.Code {
+			t.Errorf("%s: code = %s, want %s", tt.name, e.Code, errno.ErrInvalidParameterValue.Code)
+		}
+		if !strings.HasPrefix(e.Message, tt.field+" ") {
+			t.Errorf("%s: message = %q, want it to name %s", tt.name, e.Message, tt.field)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// Options 存储配置
+type Options struct {
+	// Dir 数据目录，每张表保存为一个追加写入的日志文件；为空时只保存在内存中
+	Dir string `yaml:"dir"`
+}
+
+// DB 基于 JSON 日志文件的简单存储，适合单实例部署和数据量较小的场景
+type DB struct {
+	dir string
+
+	mu     sync.Mutex
+	tables map[string]struct{}
+}
+
+// Open 打开数据目录，目录不存在时自动创建
+func Open(opts *Options) (*DB, error) {
+	db := &DB{tables: map[string]struct{}{}}
+	if opts == nil || len(opts.Dir) == 0 {
+		return db, nil
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create store dir %s: %w", opts.Dir, err)
+	}
+	db.dir = opts.Dir
+
+	return db, nil
+}
+
+// NewID 生成随机 ID
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// minCompactEntries 日志条目少于该数量时不压缩
+const minCompactEntries = 1024
+
+// Table 一张表，记录按 ID 存储
+// 每次写入只在日志文件末尾追加一行，日志中过期的条目超过有效记录数时重写为快照。
+// 记录在内存中以 JSON 保存，Get、List 返回的是副本，修改后需通过 Put 写回。
+type Table[T any] struct {
+	name string
+	path string
+
+	mu      sync.RWMutex
+	rows    map[string]json.RawMessage
+	entries int
+}
+
+// entry 日志中的一行，一次写入的记录和删除的 ID 在同一行，加载时整行生效或整行丢弃
+type entry struct {
+	Rows    map[string]json.RawMessage `json:"rows,omitempty"`
+	Deleted []string                   `json:"deleted,omitempty"`
+}
+
+// NewTable 打开表并加载已有数据，同一张表只能打开一次
+func NewTable[T any](db *DB, name string) (*Table[T], error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.tables[name]; ok {
+		return nil, fmt.Errorf("table %s is already opened", name)
+	}
+
+	t := &Table[T]{name: name, rows: map[string]json.RawMessage{}}
+	if len(db.dir) > 0 {
+		t.path = filepath.Join(db.dir, name+".jsonl")
+		if err := t.load(filepath.Join(db.dir, name+".json")); err != nil {
+			return nil, fmt.Errorf("load table %s: %w", name, err)
+		}
+	}
+
+	db.tables[name] = struct{}{}
+
+	return t, nil
+}
+
+// Get 按 ID 查询，返回记录的副本
+func (t *Table[T]) Get(id string) (*T, error) {
+	t.mu.RLock()
+	data, ok := t.rows[id]
+	t.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return decode[T](data)
+}
+
+// Put 写入或覆盖记录，写入后再修改 row 不影响表中的记录
+func (t *Table[T]) Put(id string, row *T) error {
+	return t.PutAll(map[string]*T{id: row})
+}
+
+// PutAll 写入多条记录，全部成功或全部失败
+func (t *Table[T]) PutAll(rows map[string]*T) error {
+	e := entry{Rows: map[string]json.RawMessage{}}
+	for id, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		e.Rows[id] = data
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.apply(e)
+}
+
+// Delete 删除记录，记录不存在时返回 ErrNotFound
+func (t *Table[T]) Delete(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[id]; !ok {
+		return ErrNotFound
+	}
+	return t.apply(entry{Deleted: []string{id}})
+}
+
+// List 返回满足条件的记录副本，按 ID 排序；match 为 nil 时返回全部
+func (t *Table[T]) List(match func(*T) bool) []*T {
+	t.mu.RLock()
+	ids := make([]string, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	data := make([]json.RawMessage, len(ids))
+	for i, id := range ids {
+		data[i] = t.rows[id]
+	}
+	t.mu.RUnlock()
+
+	var rows []*T
+	for _, d := range data {
+		row, err := decode[T](d)
+		if err != nil {
+			continue
+		}
+		if match == nil || match(row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// apply 把一次写入追加到日志后更新内存，调用者需持有写锁
+func (t *Table[T]) apply(e entry) error {
+	if err := t.append(e); err != nil {
+		return err
+	}
+
+	for id, data := range e.Rows {
+		t.rows[id] = data
+	}
+	for _, id := range e.Deleted {
+		delete(t.rows, id)
+	}
+
+	// 过期条目多于有效记录时重写日志，均摊后每次写入的代价与表大小无关
+	// 压缩失败不影响已追加的写入，下次写入时重试
+	if t.entries > minCompactEntries && t.entries > 2*len(t.rows) {
+		_ = t.compact()
+	}
+	return nil
+}
+
+// append 在日志末尾追加一行
+func (t *Table[T]) append(e entry) error {
+	if len(t.path) == 0 {
+		return nil
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(t.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		// 去掉写了一半的行，避免后续追加的行接在后面无法解析
+		f.Truncate(info.Size())
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	t.entries++
+	return nil
+}
+
+// compact 把全部记录写入临时文件后替换日志，避免写入中途失败损坏数据
+func (t *Table[T]) compact() error {
+	if len(t.path) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if len(t.rows) > 0 {
+		line, err := json.Marshal(entry{Rows: t.rows})
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return err
+	}
+
+	t.entries = 0
+	if len(t.rows) > 0 {
+		t.entries = 1
+	}
+	return nil
+}
+
+// load 重放日志，最后一行不完整（写入中途退出）时丢弃该行
+// 日志不存在时从旧版本整表保存的 JSON 文件 legacy 导入。
+func (t *Table[T]) load(legacy string) error {
+	f, err := os.Open(t.path)
+	if os.IsNotExist(err) {
+		return t.migrate(legacy)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		r      = bufio.NewReader(f)
+		offset int64
+	)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				return os.Truncate(t.path, offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var e entry
+		if err = json.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("line at offset %d: %w", offset, err)
+		}
+		for id, data := range e.Rows {
+			t.rows[id] = data
+		}
+		for _, id := range e.Deleted {
+			delete(t.rows, id)
+		}
+		t.entries++
+		offset += int64(len(line))
+	}
+}
+
+// migrate 导入旧版本的 JSON 文件并写入日志
+func (t *Table[T]) migrate(legacy string) error {
+	content, err := os.ReadFile(legacy)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(content) > 0 {
+		if err = json.Unmarshal(content, &t.rows); err != nil {
+			return err
+		}
+	}
+	if err = t.compact(); err != nil {
+		return err
+	}
+	return os.Remove(legacy)
+}
+
+// decode 解析记录，每次调用返回新的副本
+func decode[T any](data json.RawMessage) (*T, error) {
+	row := new(T)
+	if err := json.Unmarshal(data, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
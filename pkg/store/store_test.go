@@ -0,0 +1,141 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type row struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func openTable(t *testing.T, dir string) *Table[row] {
+	t.Helper()
+	db, err := Open(&Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := NewTable[row](db, "rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestTableCopies(t *testing.T) {
+	table := openTable(t, t.TempDir())
+
+	r := &row{Name: "a", Tags: []string{"x"}}
+	if err := table.Put("1", r); err != nil {
+		t.Fatal(err)
+	}
+	// 写入后修改参数、修改 Get 和 List 的返回值都不影响表中的记录
+	r.Tags[0] = "changed"
+	got, _ := table.Get("1")
+	got.Name = "changed"
+	table.List(nil)[0].Tags[0] = "changed"
+
+	got, err := table.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "a" || got.Tags[0] != "x" {
+		t.Errorf("Get() = %+v, want the row as written", got)
+	}
+}
+
+func TestTableReload(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*Table[row]) error
+		want  map[string]string
+	}{
+		{"put", func(tb *Table[row]) error {
+			return tb.Put("1", &row{Name: "a"})
+		}, map[string]string{"1": "a"}},
+		{"overwrite", func(tb *Table[row]) error {
+			tb.Put("1", &row{Name: "a"})
+			return tb.Put("1", &row{Name: "b"})
+		}, map[string]string{"1": "b"}},
+		{"delete", func(tb *Table[row]) error {
+			tb.Put("1", &row{Name: "a"})
+			tb.Put("2", &row{Name: "b"})
+			return tb.Delete("1")
+		}, map[string]string{"2": "b"}},
+		{"put all", func(tb *Table[row]) error {
+			return tb.PutAll(map[string]*row{"1": {Name: "a"}, "2": {Name: "b"}})
+		}, map[string]string{"1": "a", "2": "b"}},
+		{"compact", func(tb *Table[row]) error {
+			for i := 0; i < 3*minCompactEntries; i++ {
+				if err := tb.Put("1", &row{Name: "a"}); err != nil {
+					return err
+				}
+			}
+			if tb.entries > minCompactEntries+1 {
+				t.Errorf("entries = %d, want the log compacted", tb.entries)
+			}
+			return tb.Put("2", &row{Name: "b"})
+		}, map[string]string{"1": "a", "2": "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := tt.write(openTable(t, dir)); err != nil {
+				t.Fatal(err)
+			}
+			checkRows(t, openTable(t, dir), tt.want)
+		})
+	}
+}
+
+func TestTableTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	table := openTable(t, dir)
+	table.Put("1", &row{Name: "a"})
+
+	// 写入中途退出留下不完整的一行，加载时丢弃，之后的写入正常追加
+	f, err := os.OpenFile(filepath.Join(dir, "rows.jsonl"), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"rows":{"2":{"na`)
+	f.Close()
+
+	table = openTable(t, dir)
+	checkRows(t, table, map[string]string{"1": "a"})
+	if err = table.Put("3", &row{Name: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, openTable(t, dir), map[string]string{"1": "a", "3": "c"})
+}
+
+func TestTableMigrate(t *testing.T) {
+	dir := t.TempDir()
+	legacy, _ := json.Marshal(map[string]*row{"1": {Name: "a"}})
+	if err := os.WriteFile(filepath.Join(dir, "rows.json"), legacy, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checkRows(t, openTable(t, dir), map[string]string{"1": "a"})
+	if _, err := os.Stat(filepath.Join(dir, "rows.json")); !os.IsNotExist(err) {
+		t.Errorf("legacy file still exists: %v", err)
+	}
+	checkRows(t, openTable(t, dir), map[string]string{"1": "a"})
+}
+
+func checkRows(t *testing.T, table *Table[row], want map[string]string) {
+	t.Helper()
+	if got := len(table.List(nil)); got != len(want) {
+		t.Errorf("len(List()) = %d, want %d", got, len(want))
+	}
+	for id, name := range want {
+		got, err := table.Get(id)
+		if err != nil || got.Name != name {
+			t.Errorf("Get(%q) = %+v, %v, want %q", id, got, err, name)
+		}
+	}
+}
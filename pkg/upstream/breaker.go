@@ -0,0 +1,125 @@
+package upstream
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	StateClosed   = "closed"    // 正常放行
+	StateOpen     = "open"      // 快速失败
+	StateHalfOpen = "half_open" // 放行一个探测请求，成功后关闭
+)
+
+var breakers sync.Map // name => *Breaker
+
+// Breaker 上游的熔断器，连续失败达到阈值后打开，打开一段时间后放行一个探测请求
+type Breaker struct {
+	name string
+
+	mu       sync.Mutex
+	opts     BreakerOptions
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Status 熔断器状态
+type Status struct {
+	Name     string     `json:"name"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// Get 返回指定上游的熔断器，不存在时创建；opts 为当前配置，重新加载后对已有熔断器生效
+func Get(name string, opts BreakerOptions) *Breaker {
+	v, _ := breakers.LoadOrStore(name, &Breaker{name: name, state: StateClosed})
+	b := v.(*Breaker)
+
+	b.mu.Lock()
+	b.opts = opts
+	b.mu.Unlock()
+
+	return b
+}
+
+// States 返回所有熔断器的状态，按名称排序
+func States() []Status {
+	states := []Status{}
+	breakers.Range(func(_, v interface{}) bool {
+		states = append(states, v.(*Breaker).Status())
+		return true
+	})
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+// Name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow 是否放行请求，熔断器打开超时后转为半开并只放行一个探测请求
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < time.Duration(b.opts.OpenTimeout)*time.Second {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// Success 请求成功，关闭熔断器
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 请求失败，连续失败达到阈值或探测失败时打开熔断器
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || b.failures >= b.opts.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Status
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Status{Name: b.name, State: b.state, Failures: b.failures}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		s.OpenedAt = &openedAt
+	}
+	return s
+}
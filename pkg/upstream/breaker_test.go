@@ -0,0 +1,88 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	b := Get("test-breaker", BreakerOptions{FailureThreshold: 3, OpenTimeout: 30})
+
+	// 依次执行的操作，expire 模拟熔断器打开已超时
+	steps := []struct {
+		action   string
+		allowed  bool // action 为 allow 时的期望结果
+		state    string
+		failures int
+	}{
+		{"failure", false, StateClosed, 1},
+		{"failure", false, StateClosed, 2},
+		{"allow", true, StateClosed, 2},
+		{"success", false, StateClosed, 0},
+		{"failure", false, StateClosed, 1},
+		{"failure", false, StateClosed, 2},
+		{"failure", false, StateOpen, 3},
+		{"allow", false, StateOpen, 3},
+		{"expire", false, StateOpen, 3},
+		{"allow", true, StateHalfOpen, 3},
+		{"allow", false, StateHalfOpen, 3}, // 只放行一个探测请求
+		{"failure", false, StateOpen, 4},   // 探测失败重新打开
+		{"allow", false, StateOpen, 4},
+		{"expire", false, StateOpen, 4},
+		{"allow", true, StateHalfOpen, 4},
+		{"success", false, StateClosed, 0},
+		{"allow", true, StateClosed, 0},
+		{"allow", true, StateClosed, 0},
+	}
+
+	for i, s := range steps {
+		switch s.action {
+		case "allow":
+			if got := b.Allow(); got != s.allowed {
+				t.Fatalf("step %d: Allow() = %v, want %v", i, got, s.allowed)
+			}
+		case "success":
+			b.Success()
+		case "failure":
+			b.Failure()
+		case "expire":
+			b.mu.Lock()
+			b.openedAt = b.openedAt.Add(-time.Minute)
+			b.mu.Unlock()
+		}
+
+		status := b.Status()
+		if status.State != s.state || status.Failures != s.failures {
+			t.Fatalf("step %d (%s): status = %s/%d, want %s/%d", i, s.action,
+				status.State, status.Failures, s.state, s.failures)
+		}
+		if (status.OpenedAt != nil) != (s.state != StateClosed) {
+			t.Errorf("step %d: OpenedAt = %v in state %s", i, status.OpenedAt, s.state)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	a := Get("test-get-a", BreakerOptions{FailureThreshold: 1})
+	if b := Get("test-get-a", BreakerOptions{FailureThreshold: 5}); b != a {
+		t.Fatal("Get() returned a new breaker for the same name")
+	}
+
+	// 重新加载的配置对已有熔断器生效
+	a.Failure()
+	if got := a.Status().State; got != StateClosed {
+		t.Errorf("state after one failure = %s, want %s with the new threshold", got, StateClosed)
+	}
+
+	Get("test-get-b", BreakerOptions{})
+	var names []string
+	for _, s := range States() {
+		names = append(names, s.Name)
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Errorf("States() = %q, want sorted by name", names)
+			break
+		}
+	}
+}
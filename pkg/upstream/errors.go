@@ -0,0 +1,108 @@
+package upstream
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lingolift/errno"
+)
+
+// SDK 返回的错误只有文本：
+//
+//	连接、收发失败：voice_id: xxx, error: ...
+//	启动时被拒绝：  voice_id: xxx, code: 4002, message: ...
+//	评测中失败：    VoiceID: xxx, error code 4007, message: ...
+var codePattern = regexp.MustCompile(`(?:, code: |, error code )(\d+), message: (.*)$`)
+
+// 上游错误码，4xxx 为请求方的问题，5xxx 为上游服务端错误
+const (
+	codeInvalidParameter   = 4001 // 参数不合法，包括参考文本超长
+	codeAuthFailed         = 4002 // 鉴权失败
+	codeNotActivated       = 4003 // 服务未开通
+	codeQuotaExhausted     = 4004 // 资源包耗尽
+	codeArrears            = 4005 // 账户欠费
+	codeThrottled          = 4006 // 并发超限
+	codeInvalidAudio       = 4007 // 音频解码失败
+	codeAudioTimeout       = 4008 // 客户端数据上传超时
+	codeClientDisconnected = 4009 // 客户端连接断开
+	codeUnknownMessage     = 4010 // 客户端上传未知文本消息
+	codeServerError        = 5000 // 后台错误
+)
+
+// Code 从 SDK 的错误中解析上游错误码，没有错误码时返回 0
+func Code(err error) int {
+	code, _ := parse(err)
+	return code
+}
+
+// Error 将 SDK 和上游的错误转换为 errno.Err，原始错误保存在 RawErr 中
+// 错误类型和是否可以重试只由错误码决定：参数不合法时按消息区分参考文本超长，两者都不可重试。
+// 已是 errno.Err 的错误原样返回，无法识别的错误按内部错误处理。
+func Error(err error) errno.Err {
+	var e errno.Err
+	if errors.As(err, &e) {
+		return e
+	}
+
+	code, message := parse(err)
+	switch code {
+	case 0:
+		// 没有错误码时为连接、收发阶段的网络错误
+		if sdkError(err) {
+			return errno.ErrUpstreamUnavailable.WithRawErr(err)
+		}
+		return errno.ErrInternalServer.WithRawErr(err)
+	case codeInvalidParameter:
+		if textTooLong(message) {
+			return errno.ErrTextTooLong.WithFmtAndRawErr(message, err)
+		}
+		return errno.ErrInvalidParameter.WithRawErr(err)
+	case codeAuthFailed, codeNotActivated:
+		return errno.ErrUpstreamAuthFailed.WithRawErr(err)
+	case codeQuotaExhausted, codeArrears:
+		return errno.ErrQuotaExhausted.WithRawErr(err)
+	case codeThrottled:
+		return errno.ErrUpstreamThrottled.WithRawErr(err)
+	case codeInvalidAudio:
+		return errno.ErrInvalidAudio.WithRawErr(err)
+	case codeAudioTimeout:
+		return errno.ErrAudioTimeout.WithRawErr(err)
+	case codeClientDisconnected, codeUnknownMessage:
+		// 本服务与上游之间的问题，与服务端错误一样可以重试
+		return errno.ErrUpstreamFailed.WithRawErr(err)
+	}
+
+	// 其他 4xxx 为确定性的请求错误，重试和切换引擎都无法解决
+	if code < codeServerError {
+		return errno.ErrInvalidParameter.WithRawErr(err)
+	}
+	return errno.ErrUpstreamFailed.WithRawErr(err)
+}
+
+// Retryable 是否为可重试的错误：连接失败、超时、并发超限和服务端错误
+func Retryable(err error) bool {
+	return Error(err).Retryable
+}
+
+func parse(err error) (int, string) {
+	m := codePattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, ""
+	}
+	code, _ := strconv.Atoi(m[1])
+	return code, m[2]
+}
+
+func sdkError(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "voice_id: ") || strings.HasPrefix(msg, "VoiceID: ")
+}
+
+// textTooLong 参数不合法时按消息区分参考文本超长，上游没有单独的错误码
+func textTooLong(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "text") &&
+		(strings.Contains(message, "long") || strings.Contains(message, "length") || strings.Contains(message, "exceed"))
+}
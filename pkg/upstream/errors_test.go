@@ -0,0 +1,65 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"lingolift/errno"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		err       error
+		code      string
+		retryable bool
+	}{
+		{errors.New("voice_id: v1, error: dial tcp: i/o timeout"), "UpstreamUnavailable", true},
+		{errors.New("voice_id: v1, code: 4002, message: auth failed"), "UpstreamAuthFailed", false},
+		{errors.New("voice_id: v1, code: 4003, message: not activated"), "UpstreamAuthFailed", false},
+		{errors.New("voice_id: v1, code: 4004, message: no quota"), "QuotaExhausted", false},
+		{errors.New("voice_id: v1, code: 4005, message: arrears"), "QuotaExhausted", false},
+		{errors.New("voice_id: v1, code: 4006, message: too many requests"), "UpstreamThrottled", true},
+		{errors.New("VoiceID: v1, error code 4007, message: audio decode failed"), "InvalidAudio", false},
+		{errors.New("VoiceID: v1, error code 4008, message: no audio"), "AudioTimeout", true},
+		{errors.New("VoiceID: v1, error code 4009, message: client disconnected"), "UpstreamFailed", true},
+		{errors.New("VoiceID: v1, error code 4010, message: unknown message"), "UpstreamFailed", true},
+		{errors.New("VoiceID: v1, error code 4099, message: something new"), "InvalidParameter", false},
+		{errors.New("VoiceID: v1, error code 5000, message: server error"), "UpstreamFailed", true},
+		{errors.New("voice_id: v1, code: 4001, message: ref_text is invalid"), "InvalidParameter", false},
+		{errors.New("voice_id: v1, code: 4001, message: ref text length exceeds the limit"), "TextTooLong", false},
+		{errors.New("VoiceID: v1, error code 5000, message: text too long"), "UpstreamFailed", true},
+		{errors.New("recognizer not running"), "ServiceUnavailable", false},
+		{errno.ErrServiceTimeout.WithRawErr(ErrBreakerOpen), "ServiceTimeout", false},
+		{fmt.Errorf("start: %w", errno.ErrUpstreamTimeout.WithRawErr(errors.New("timed out"))), "UpstreamTimeout", true},
+	}
+
+	for _, tt := range tests {
+		got := Error(tt.err)
+		if got.Code != tt.code || got.Retryable != tt.retryable {
+			t.Errorf("Error(%q) = %s (retryable %v), want %s (retryable %v)",
+				tt.err, got.Code, got.Retryable, tt.code, tt.retryable)
+		}
+		if Retryable(tt.err) != tt.retryable {
+			t.Errorf("Retryable(%q) = %v, want %v", tt.err, !tt.retryable, tt.retryable)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("voice_id: v1, code: 4002, message: auth failed"), 4002},
+		{errors.New("VoiceID: v1, error code 4007, message: code: 1, message: nested"), 4007},
+		{errors.New("voice_id: v1, error: EOF"), 0},
+		{errors.New("plain error"), 0},
+	}
+
+	for _, tt := range tests {
+		if got := Code(tt.err); got != tt.want {
+			t.Errorf("Code(%q) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"lingolift/errno"
+)
+
+// ErrBreakerOpen 熔断器打开，上游暂不可用
+var ErrBreakerOpen = errors.New("upstream circuit breaker is open")
+
+// Options 连接上游语音服务的超时、重试和熔断配置
+type Options struct {
+	// ConnectTimeout 启动识别器（建立连接并等待握手结果）的超时时间（秒）
+	ConnectTimeout int `yaml:"connect_timeout"`
+
+	// MaxRetries 可重试错误的最大重试次数，0 表示不重试
+	MaxRetries int `yaml:"max_retries"`
+
+	// RetryBackoff 首次重试前的等待时间（毫秒），之后每次翻倍并加入随机抖动
+	RetryBackoff int `yaml:"retry_backoff"`
+
+	// MaxBackoff 重试等待时间的上限（毫秒）
+	MaxBackoff int `yaml:"max_backoff"`
+
+	Breaker BreakerOptions `yaml:"breaker"`
+}
+
+// BreakerOptions 熔断配置
+type BreakerOptions struct {
+	// FailureThreshold 连续失败多少次后打开熔断器
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// OpenTimeout 熔断器打开后多久（秒）放行一个探测请求
+	OpenTimeout int `yaml:"open_timeout"`
+}
+
+// Check 检查配置并填充默认值
+func (o *Options) Check() error {
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = 5
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("upstream max_retries must not be negative")
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 200
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 2000
+	}
+	if o.MaxBackoff < o.RetryBackoff {
+		return fmt.Errorf("upstream max_backoff must not be less than retry_backoff")
+	}
+	if o.Breaker.FailureThreshold <= 0 {
+		o.Breaker.FailureThreshold = 5
+	}
+	if o.Breaker.OpenTimeout <= 0 {
+		o.Breaker.OpenTimeout = 30
+	}
+	return nil
+}
+
+// Timeout 启动识别器的超时时间
+func (o *Options) Timeout() time.Duration {
+	return time.Duration(o.ConnectTimeout) * time.Second
+}
+
+// Do 在熔断器允许时调用 start，可重试的错误按指数退避加随机抖动重试
+// 熔断器打开时返回 errno.ErrServiceTimeout（不可重试，避免客户端继续冲击上游），其他错误转换为对应的 errno.Err。
+func Do(b *Breaker, opts *Options, start func() error) error {
+	if !b.Allow() {
+		log.Printf("上游熔断中，拒绝新会话: %s", b.Name())
+		return errno.ErrServiceTimeout.WithRawErr(ErrBreakerOpen)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := start()
+		if err == nil {
+			b.Success()
+			return nil
+		}
+
+		e := Error(err)
+		if !e.Retryable {
+			// 上游有响应（包括鉴权失败、参数错误等）说明服务可用
+			log.Printf("启动识别器失败: %v", err)
+			b.Success()
+			return e
+		}
+
+		b.Failure()
+		if attempt >= opts.MaxRetries {
+			log.Printf("启动识别器失败，重试次数已用完: %v", err)
+			return e
+		}
+
+		wait := backoff(opts, attempt)
+		log.Printf("启动识别器失败，%v 后第 %d/%d 次重试: %v", wait, attempt+1, opts.MaxRetries, err)
+		time.Sleep(wait)
+
+		if !b.Allow() {
+			return errno.ErrServiceTimeout.WithRawErr(ErrBreakerOpen)
+		}
+	}
+}
+
+// backoff 第 attempt 次重试前的等待时间：指数退避，取 [d/2, d] 之间的随机值
+func backoff(opts *Options, attempt int) time.Duration {
+	d := opts.RetryBackoff << attempt
+	if d > opts.MaxBackoff || d <= 0 {
+		d = opts.MaxBackoff
+	}
+	return time.Duration(d/2+rand.Intn(d/2+1)) * time.Millisecond
+}
@@ -0,0 +1,126 @@
+package upstream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"lingolift/errno"
+)
+
+func TestDo(t *testing.T) {
+	serverErr := errors.New("VoiceID: v1, error code 5000, message: server error")
+	authErr := errors.New("voice_id: v1, code: 4002, message: auth failed")
+
+	tests := []struct {
+		name      string
+		threshold int
+		open      bool    // 调用前熔断器已打开
+		errs      []error // 每次调用 start 的结果，用完后返回 nil
+		code      string
+		calls     int
+		state     string
+	}{
+		{"success", 10, false, nil, "", 1, StateClosed},
+		{"retry then success", 10, false, []error{serverErr, serverErr}, "", 3, StateClosed},
+		{"retries exhausted", 10, false, []error{serverErr, serverErr, serverErr}, "UpstreamFailed", 3, StateClosed},
+		{"not retryable", 10, false, []error{authErr}, "UpstreamAuthFailed", 1, StateClosed},
+		{"breaker opens while retrying", 2, false, []error{serverErr, serverErr, serverErr}, "ServiceTimeout", 2, StateOpen},
+		{"breaker open", 10, true, nil, "ServiceTimeout", 0, StateOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Breaker{name: tt.name, state: StateClosed, opts: BreakerOptions{FailureThreshold: tt.threshold, OpenTimeout: 30}}
+			if tt.open {
+				b.state, b.openedAt = StateOpen, time.Now()
+			}
+			opts := &Options{MaxRetries: 2, RetryBackoff: 1, MaxBackoff: 2}
+
+			var calls int
+			err := Do(b, opts, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			var code string
+			if err != nil {
+				var e errno.Err
+				if !errors.As(err, &e) {
+					t.Fatalf("Do() = %v, want errno.Err", err)
+				}
+				code = e.Code
+			}
+			if code != tt.code {
+				t.Errorf("Do() = %v, want %q", err, tt.code)
+			}
+			if calls != tt.calls {
+				t.Errorf("start called %d times, want %d", calls, tt.calls)
+			}
+			if got := b.Status().State; got != tt.state {
+				t.Errorf("breaker state = %s, want %s", got, tt.state)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	opts := &Options{RetryBackoff: 100, MaxBackoff: 1000}
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond, 800 * time.Millisecond},
+		{4, 500 * time.Millisecond, 1000 * time.Millisecond},
+		{40, 500 * time.Millisecond, 1000 * time.Millisecond},
+		{70, 500 * time.Millisecond, 1000 * time.Millisecond}, // 移位溢出
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := backoff(opts, tt.attempt); got < tt.min || got > tt.max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
+
+func TestOptionsCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    Options
+		wantErr bool
+	}{
+		{"defaults", Options{}, Options{
+			ConnectTimeout: 5, RetryBackoff: 200, MaxBackoff: 2000,
+			Breaker: BreakerOptions{FailureThreshold: 5, OpenTimeout: 30},
+		}, false},
+		{"custom", Options{ConnectTimeout: 1, MaxRetries: 3, RetryBackoff: 50, MaxBackoff: 50,
+			Breaker: BreakerOptions{FailureThreshold: 2, OpenTimeout: 5}}, Options{
+			ConnectTimeout: 1, MaxRetries: 3, RetryBackoff: 50, MaxBackoff: 50,
+			Breaker: BreakerOptions{FailureThreshold: 2, OpenTimeout: 5},
+		}, false},
+		{"negative retries", Options{MaxRetries: -1}, Options{}, true},
+		{"max below initial backoff", Options{RetryBackoff: 500, MaxBackoff: 100}, Options{}, true},
+	}
+
+	for _, tt := range tests {
+		opts := tt.opts
+		err := opts.Check()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Check() = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && opts != tt.want {
+			t.Errorf("%s: Check() filled %+v, want %+v", tt.name, opts, tt.want)
+		}
+	}
+}